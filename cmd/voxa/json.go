@@ -0,0 +1,355 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/wirekit/voxa"
+	"github.com/wirekit/voxa/codecs"
+)
+
+// toJSON writes every frame within input as a line of JSON.
+func toJSON(w io.Writer, input []byte) error {
+	for offset := 0; offset < len(input); {
+		frame, content, rest, err := codecs.NextFrame(input[offset:])
+		if err != nil {
+			return fmt.Errorf("offset %d: %s", offset, err)
+		}
+
+		value, err := jsonValue(frame, content)
+		if err != nil {
+			return err
+		}
+
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+
+		if _, err := w.Write(append(encoded, '\n')); err != nil {
+			return err
+		}
+
+		offset = len(input) - len(rest)
+	}
+
+	return nil
+}
+
+// jsonValue returns the value of provided frame as a value accepted by
+// encoding/json. Records become objects keyed by field id, described
+// records objects keyed by field name, maps objects keyed by their
+// formatted keys, unions the value they hold and scalars the values
+// jsonScalar returns.
+func jsonValue(frame []byte, content []byte) (interface{}, error) {
+	atom, _, data, err := codecs.ReadHeader(content)
+	if err != nil {
+		return nil, err
+	}
+
+	switch atom {
+	case voxa.Record:
+		return jsonObject(data, func(id voxa.FieldID) (string, bool) {
+			return strconv.Itoa(int(id)), true
+		})
+	case voxa.Schema:
+		names := map[voxa.FieldID]string{}
+		for len(data) > 0 {
+			_, entry, rest, err := codecs.NextFrame(data)
+			if err != nil {
+				return nil, err
+			}
+
+			// the last frame is the described record.
+			if len(rest) == 0 {
+				_, _, fields, err := codecs.ReadHeader(entry)
+				if err != nil {
+					return nil, err
+				}
+
+				return jsonObject(fields, func(id voxa.FieldID) (string, bool) {
+					name, ok := names[id]
+					return name, ok
+				})
+			}
+
+			name, id, _, err := (codecs.HeaderCodec{}).BinaryToField(entry)
+			if err != nil {
+				return nil, err
+			}
+
+			names[id] = name
+			data = rest
+		}
+		return nil, codecs.ErrNoDescribedRecord
+	case voxa.Union:
+		_, value, err := codecs.ReadUnion(content)
+		if err != nil {
+			return nil, err
+		}
+
+		valueFrame, valueContent, _, err := codecs.NextFrame(value)
+		if err != nil {
+			return nil, err
+		}
+		return jsonValue(valueFrame, valueContent)
+	case voxa.List:
+		list := []interface{}{}
+		for len(data) > 0 {
+			itemFrame, itemContent, rest, err := codecs.NextFrame(data)
+			if err != nil {
+				return nil, err
+			}
+
+			item, err := jsonValue(itemFrame, itemContent)
+			if err != nil {
+				return nil, err
+			}
+
+			list = append(list, item)
+			data = rest
+		}
+		return list, nil
+	case voxa.Map:
+		object := map[string]interface{}{}
+		for len(data) > 0 {
+			keyFrame, keyContent, rest, err := codecs.NextFrame(data)
+			if err != nil {
+				return nil, err
+			}
+
+			valueFrame, valueContent, rest, err := codecs.NextFrame(rest)
+			if err != nil {
+				return nil, err
+			}
+
+			key, err := jsonValue(keyFrame, keyContent)
+			if err != nil {
+				return nil, err
+			}
+
+			value, err := jsonValue(valueFrame, valueContent)
+			if err != nil {
+				return nil, err
+			}
+
+			object[fmt.Sprint(key)] = value
+			data = rest
+		}
+		return object, nil
+	}
+
+	var value interface{}
+	if err := voxa.Unmarshal(frame, &value); err != nil {
+		return nil, err
+	}
+	return jsonScalar(value), nil
+}
+
+// jsonScalar returns provided scalar or packed value as a value accepted by
+// encoding/json, where complex numbers become a [real, imaginary] pair and
+// NaN or infinite floats a string, such as "NaN" or "+Inf".
+func jsonScalar(value interface{}) interface{} {
+	switch v := value.(type) {
+	case float32:
+		return jsonFloat(float64(v), 32)
+	case float64:
+		return jsonFloat(v, 64)
+	case complex64:
+		return []interface{}{jsonFloat(float64(real(v)), 32), jsonFloat(float64(imag(v)), 32)}
+	case complex128:
+		return []interface{}{jsonFloat(real(v), 64), jsonFloat(imag(v), 64)}
+	case []interface{}:
+		for i := range v {
+			v[i] = jsonScalar(v[i])
+		}
+	}
+	return value
+}
+
+// jsonFloat returns provided float of provided bit size, or it's strconv
+// form when encoding/json does not accept it.
+func jsonFloat(f float64, bitSize int) interface{} {
+	switch {
+	case math.IsNaN(f) || math.IsInf(f, 0):
+		return strconv.FormatFloat(f, 'g', -1, bitSize)
+	case bitSize == 32:
+		return float32(f)
+	}
+	return f
+}
+
+// jsonObject returns the fields within provided record data as an object
+// keyed by the name returned for their FieldID. Fields without a name are
+// skipped.
+func jsonObject(data []byte, name func(voxa.FieldID) (string, bool)) (interface{}, error) {
+	object := map[string]interface{}{}
+	for len(data) > 0 {
+		fieldFrame, fieldContent, rest, err := codecs.NextFrame(data)
+		if err != nil {
+			return nil, err
+		}
+
+		_, id, _, err := codecs.ReadHeader(fieldContent)
+		if err != nil {
+			return nil, err
+		}
+
+		if key, ok := name(id); ok {
+			value, err := jsonValue(fieldFrame, fieldContent)
+			if err != nil {
+				return nil, err
+			}
+			object[key] = value
+		}
+
+		data = rest
+	}
+	return object, nil
+}
+
+// fromJSON encodes every JSON value within input as a frame.
+func fromJSON(w io.Writer, input []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(input))
+	decoder.UseNumber()
+
+	var buffer []byte
+	for {
+		var value interface{}
+		if err := decoder.Decode(&value); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		encoded, err := appendJSON(value, 0, buffer[:0])
+		if err != nil {
+			return err
+		}
+
+		if _, err := w.Write(encoded); err != nil {
+			return err
+		}
+
+		buffer = encoded
+	}
+}
+
+// appendJSON encodes provided JSON value as a frame marked with provided
+// FieldID, appending it into provided byte slice.
+func appendJSON(value interface{}, id voxa.FieldID, c []byte) ([]byte, error) {
+	switch v := value.(type) {
+	case nil:
+		return codecs.AppendNull(c, id), nil
+	case bool:
+		return codecs.AppendFrame(codecs.BooleanCodec{}, v, id, c)
+	case string:
+		return codecs.AppendFrame(codecs.TextCodec{}, v, id, c)
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return codecs.AppendFrame(codecs.IntCodec{}, i, id, c)
+		}
+
+		f, err := v.Float64()
+		if err != nil {
+			return c, err
+		}
+		return codecs.AppendFrame(codecs.FloatCodec{}, f, id, c)
+	case []interface{}:
+		c, start := codecs.ReserveFrame(c)
+		c = codecs.AppendHeader(c, voxa.List, id)
+
+		var err error
+		for i, item := range v {
+			if c, err = appendJSON(item, voxa.FieldID(i), c); err != nil {
+				return c[:start], err
+			}
+		}
+
+		return codecs.CloseFrame(c, start), nil
+	case map[string]interface{}:
+		if ids, ok := fieldIDs(v); ok {
+			return appendRecord(v, ids, id, c)
+		}
+		return appendMap(v, id, c)
+	}
+
+	return c, fmt.Errorf("unsupported JSON value %T", value)
+}
+
+// fieldIDs returns the keys of provided object sorted as FieldIDs, if all
+// of them are valid FieldIDs.
+func fieldIDs(object map[string]interface{}) ([]voxa.FieldID, bool) {
+	if len(object) == 0 {
+		return nil, false
+	}
+
+	ids := make([]voxa.FieldID, 0, len(object))
+	for key := range object {
+		id, err := strconv.ParseUint(key, 10, 32)
+		if err != nil || strconv.FormatUint(id, 10) != key {
+			return nil, false
+		}
+		ids = append(ids, voxa.FieldID(id))
+	}
+
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids, true
+}
+
+// appendRecord encodes provided object as a record whose fields are marked
+// by their key.
+func appendRecord(object map[string]interface{}, ids []voxa.FieldID, id voxa.FieldID, c []byte) ([]byte, error) {
+	c, start := codecs.ReserveFrame(c)
+	c = codecs.AppendHeader(c, voxa.Record, id)
+
+	var err error
+	for _, field := range ids {
+		value := object[strconv.Itoa(int(field))]
+		if c, err = appendJSON(value, field, c); err != nil {
+			return c[:start], err
+		}
+	}
+
+	return codecs.CloseFrame(c, start), nil
+}
+
+// appendMap encodes provided object as a map with string keys, sorting
+// entries by their encoded keys as the codecs.MapCodec does.
+func appendMap(object map[string]interface{}, id voxa.FieldID, c []byte) ([]byte, error) {
+	type entry struct {
+		key, value []byte
+	}
+
+	entries := make([]entry, 0, len(object))
+	for key, value := range object {
+		encodedKey, err := codecs.AppendFrame(codecs.TextCodec{}, key, 0, nil)
+		if err != nil {
+			return c, err
+		}
+
+		encodedValue, err := appendJSON(value, 1, nil)
+		if err != nil {
+			return c, err
+		}
+
+		entries = append(entries, entry{key: encodedKey, value: encodedValue})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return bytes.Compare(entries[i].key, entries[j].key) < 0
+	})
+
+	c, start := codecs.ReserveFrame(c)
+	c = codecs.AppendHeader(c, voxa.Map, id)
+	for _, e := range entries {
+		c = append(append(c, e.key...), e.value...)
+	}
+
+	return codecs.CloseFrame(c, start), nil
+}
@@ -0,0 +1,74 @@
+// Command voxa inspects, validates and converts voxa payloads, reading a
+// sequence of frames from a file or stdin.
+//
+// Usage:
+//
+//	voxa dump [file]      print an annotated hex tree of every frame
+//	voxa tojson [file]    print every frame as a line of JSON
+//	voxa fromjson [file]  encode every JSON value as a frame
+//	voxa validate [file]  check every frame is well formed
+//
+// Records are converted into JSON objects keyed by their field ids, and
+// described records into objects keyed by their field names. When encoding
+// JSON, objects whose keys are all field ids become records and all others
+// become maps, integers are encoded as int64 and other numbers as float64.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+
+	_ "github.com/wirekit/voxa/codecs"
+)
+
+// commands maps the name of every subcommand to it's function, which reads
+// it's input from provided byte slice and writes into provided writer.
+var commands = map[string]func(io.Writer, []byte) error{
+	"dump":     dump,
+	"tojson":   toJSON,
+	"fromjson": fromJSON,
+	"validate": validate,
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("voxa: ")
+
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: voxa <dump|tojson|fromjson|validate> [file]\n")
+		fmt.Fprintf(os.Stderr, "Reads from stdin when no file is provided.\n")
+	}
+	flag.Parse()
+
+	if flag.NArg() < 1 || flag.NArg() > 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	command, ok := commands[flag.Arg(0)]
+	if !ok {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	input, err := readInput(flag.Arg(1))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := command(os.Stdout, input); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// readInput reads the content of provided file, or stdin if empty.
+func readInput(file string) ([]byte, error) {
+	if file == "" || file == "-" {
+		return ioutil.ReadAll(os.Stdin)
+	}
+	return ioutil.ReadFile(file)
+}
@@ -0,0 +1,310 @@
+package main
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influx6/faux/tests"
+	"github.com/wirekit/voxa"
+	"github.com/wirekit/voxa/codecs"
+)
+
+type user struct {
+	Name      string         `id:"1"`
+	Age       int            `id:"2"`
+	Interests []string       `id:"3"`
+	Scores    map[string]int `id:"4"`
+}
+
+var sample = user{
+	Name:      "bob",
+	Age:       32,
+	Interests: []string{"daydreaming", "hacking"},
+	Scores:    map[string]int{"chess": 1200},
+}
+
+func TestDump(t *testing.T) {
+	encoded, err := voxa.Marshal(sample)
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded value")
+	}
+	tests.Passed("Should have successfully encoded value")
+
+	var out bytes.Buffer
+	if err := dump(&out, encoded); err != nil {
+		tests.FailedWithError(err, "Should have successfully dumped payload")
+	}
+	tests.Passed("Should have successfully dumped payload")
+
+	for _, expected := range []string{
+		"record id=0 length=",
+		`  text id=1 "bob"`,
+		"  sint id=2 32",
+		"  list id=3 length=",
+		`    text id=1 "hacking"`,
+		"  map id=4 length=",
+		`    text id=0 "chess"`,
+	} {
+		if !strings.Contains(out.String(), expected) {
+			tests.Info("Received: \n%s", out.String())
+			tests.Failed("Should have found %q in dump", expected)
+		}
+	}
+	tests.Passed("Should have found frames in dump")
+}
+
+func TestDump_Described(t *testing.T) {
+	encoded, err := codecs.RecordCodec{Described: true}.NativeToBinary(sample, nil)
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded value")
+	}
+	tests.Passed("Should have successfully encoded value")
+
+	var out bytes.Buffer
+	if err := dump(&out, encoded); err != nil {
+		tests.FailedWithError(err, "Should have successfully dumped payload")
+	}
+	tests.Passed("Should have successfully dumped payload")
+
+	if !strings.Contains(out.String(), `field id=1 atom=text name="Name"`) {
+		tests.Info("Received: \n%s", out.String())
+		tests.Failed("Should have found schema entries in dump")
+	}
+	tests.Passed("Should have found schema entries in dump")
+}
+
+func TestDump_Union(t *testing.T) {
+	voxa.RegisterType(7, user{})
+
+	encoded, err := voxa.Marshal(map[string]interface{}{"owner": user{Name: "ana"}})
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded value")
+	}
+	tests.Passed("Should have successfully encoded value")
+
+	var out bytes.Buffer
+	if err := dump(&out, encoded); err != nil {
+		tests.FailedWithError(err, "Should have successfully dumped payload")
+	}
+	tests.Passed("Should have successfully dumped payload")
+
+	for _, expected := range []string{"  union id=1 type=7", "    record id=0 length=", `      text id=1 "ana"`} {
+		if !strings.Contains(out.String(), expected) {
+			tests.Info("Received: \n%s", out.String())
+			tests.Failed("Should have found %q in dump", expected)
+		}
+	}
+	tests.Passed("Should have found union frames in dump")
+
+	out.Reset()
+	if err := toJSON(&out, encoded); err != nil {
+		tests.FailedWithError(err, "Should have successfully converted payload into JSON")
+	}
+
+	if expected := `{"owner":{"1":"ana","2":0,"3":null,"4":null}}` + "\n"; out.String() != expected {
+		tests.Info("Received: %s", out.String())
+		tests.Info("Expected: %s", expected)
+		tests.Failed("Should have converted union into the value it holds")
+	}
+	tests.Passed("Should have converted union into the value it holds")
+}
+
+func TestToJSON(t *testing.T) {
+	encoded, err := voxa.Marshal(sample)
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded value")
+	}
+	tests.Passed("Should have successfully encoded value")
+
+	described, err := codecs.RecordCodec{Described: true}.NativeToBinary(sample, nil)
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded described value")
+	}
+	tests.Passed("Should have successfully encoded described value")
+
+	var out bytes.Buffer
+	if err := toJSON(&out, append(encoded, described...)); err != nil {
+		tests.FailedWithError(err, "Should have successfully converted payload into JSON")
+	}
+	tests.Passed("Should have successfully converted payload into JSON")
+
+	expected := `{"1":"bob","2":32,"3":["daydreaming","hacking"],"4":{"chess":1200}}` + "\n" +
+		`{"Age":32,"Interests":["daydreaming","hacking"],"Name":"bob","Scores":{"chess":1200}}` + "\n"
+	if out.String() != expected {
+		tests.Info("Received: %s", out.String())
+		tests.Info("Expected: %s", expected)
+		tests.Failed("Should have received expected JSON")
+	}
+	tests.Passed("Should have received expected JSON")
+}
+
+func TestToJSON_Atoms(t *testing.T) {
+	voxa.RegisterType(7, user{})
+
+	// Int, Int32 and Int64 are only written by older payloads.
+	legacy := func(atom voxa.Atom, value []byte) []byte {
+		c, start := codecs.ReserveFrame(nil)
+		return codecs.CloseFrame(append(codecs.AppendHeader(c, atom, 0), value...), start)
+	}
+
+	marshal := func(v interface{}) []byte {
+		encoded, err := voxa.Marshal(v)
+		if err != nil {
+			tests.FailedWithError(err, "Should have successfully encoded %#v", v)
+		}
+		return encoded
+	}
+
+	described, err := codecs.RecordCodec{Described: true}.NativeToBinary(user{Name: "ana"}, nil)
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded described value")
+	}
+
+	var union interface{} = user{Name: "ana"}
+
+	seen := map[voxa.Atom]bool{}
+	for _, item := range []struct {
+		frame    []byte
+		expected string
+	}{
+		{marshal("bob"), `"bob"`},
+		{legacy(voxa.Int, codecs.EncodeVarInt64(3)), `3`},
+		{marshal(int8(-3)), `-3`},
+		{marshal(int16(-3)), `-3`},
+		{legacy(voxa.Int32, codecs.EncodeVarInt32(3)), `3`},
+		{legacy(voxa.Int64, codecs.EncodeVarInt64(3)), `3`},
+		{marshal(uint(3)), `3`},
+		{marshal(uint8(3)), `3`},
+		{marshal(uint16(3)), `3`},
+		{marshal(uint32(3)), `3`},
+		{marshal(uint64(3)), `3`},
+		{marshal(true), `true`},
+		{marshal(float32(1.5)), `1.5`},
+		{marshal(2.5), `2.5`},
+		{marshal([2]byte{1, 2}), `"AQI="`},
+		{marshal([]string{"a"}), `["a"]`},
+		{marshal(struct {
+			Age int `id:"1"`
+		}{Age: 3}), `{"1":3}`},
+		{marshal(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)), `"2020-01-02T03:04:05Z"`},
+		{marshal(-3), `-3`},
+		{marshal(int32(-3)), `-3`},
+		{marshal(int64(-3)), `-3`},
+		{marshal(map[string]int{"a": 1}), `{"a":1}`},
+		{described, `{"Age":0,"Interests":null,"Name":"ana","Scores":null}`},
+		{codecs.AppendNull(nil, 0), `null`},
+		{marshal([]complex64{1 + 2i}), `[[1,2]]`},
+		{marshal(complex64(1 + 2i)), `[1,2]`},
+		{marshal(complex(1.5, -2)), `[1.5,-2]`},
+		{marshal(math.Inf(1)), `"+Inf"`},
+		{marshal(float32(math.Inf(-1))), `"-Inf"`},
+		{marshal([]float64{1, math.NaN()}), `[1,"NaN"]`},
+		{marshal(complex(math.NaN(), 1)), `["NaN",1]`},
+		{marshal(&union), `{"1":"ana","2":0,"3":null,"4":null}`},
+	} {
+		_, content, _, err := codecs.NextFrame(item.frame)
+		if err != nil {
+			tests.FailedWithError(err, "Should have successfully read frame")
+		}
+
+		atom := voxa.Atom(content[0])
+		seen[atom] = true
+
+		var out bytes.Buffer
+		if err := toJSON(&out, item.frame); err != nil {
+			tests.FailedWithError(err, "Should have successfully converted %s frame into JSON", atom)
+		}
+
+		if out.String() != item.expected+"\n" {
+			tests.Info("Received: %s", out.String())
+			tests.Info("Expected: %s", item.expected)
+			tests.Failed("Should have converted %s frame into expected JSON", atom)
+		}
+	}
+	tests.Passed("Should have converted frames into expected JSON")
+
+	// Bit is not written nor read by the codecs.
+	for atom := voxa.Text; atom <= voxa.Union; atom++ {
+		if atom != voxa.Bit && !seen[atom] {
+			tests.Failed("Should have converted a %s frame into JSON", atom)
+		}
+	}
+	tests.Passed("Should have converted every atom into JSON")
+}
+
+func TestFromJSON(t *testing.T) {
+	input := `{"1":"bob","2":32,"3":["daydreaming","hacking"],"4":{"chess":1200}}`
+
+	var encoded bytes.Buffer
+	if err := fromJSON(&encoded, []byte(input)); err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded JSON")
+	}
+	tests.Passed("Should have successfully encoded JSON")
+
+	var res user
+	if err := voxa.Unmarshal(encoded.Bytes(), &res); err != nil {
+		tests.FailedWithError(err, "Should have successfully decoded encoded JSON")
+	}
+	tests.Passed("Should have successfully decoded encoded JSON")
+
+	if res.Name != sample.Name || res.Age != sample.Age || res.Scores["chess"] != 1200 ||
+		strings.Join(res.Interests, ",") != strings.Join(sample.Interests, ",") {
+		tests.Info("Received: %#v", res)
+		tests.Failed("Should have matching elements between input and res")
+	}
+	tests.Passed("Should have matching elements between input and res")
+
+	var out bytes.Buffer
+	if err := toJSON(&out, encoded.Bytes()); err != nil {
+		tests.FailedWithError(err, "Should have successfully converted payload into JSON")
+	}
+
+	if out.String() != input+"\n" {
+		tests.Info("Received: %s", out.String())
+		tests.Failed("Should have received input JSON back")
+	}
+	tests.Passed("Should have received input JSON back")
+}
+
+func TestFromJSON_Null(t *testing.T) {
+	input := `{"1":null,"3":["hacking",null]}`
+
+	var encoded bytes.Buffer
+	if err := fromJSON(&encoded, []byte(input)); err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded JSON with nulls")
+	}
+	tests.Passed("Should have successfully encoded JSON with nulls")
+
+	var out bytes.Buffer
+	if err := toJSON(&out, encoded.Bytes()); err != nil {
+		tests.FailedWithError(err, "Should have successfully converted payload into JSON")
+	}
+
+	if out.String() != input+"\n" {
+		tests.Info("Received: %s", out.String())
+		tests.Failed("Should have received input JSON back")
+	}
+	tests.Passed("Should have received input JSON back")
+}
+
+func TestValidate(t *testing.T) {
+	encoded, err := voxa.Marshal(sample)
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded value")
+	}
+	tests.Passed("Should have successfully encoded value")
+
+	var out bytes.Buffer
+	if err := validate(&out, encoded); err != nil {
+		tests.FailedWithError(err, "Should have successfully validated payload")
+	}
+	tests.Passed("Should have successfully validated payload")
+
+	if err := validate(&out, encoded[:len(encoded)-3]); err == nil {
+		tests.Failed("Should have failed to validate truncated payload")
+	}
+	tests.Passed("Should have failed to validate truncated payload")
+}
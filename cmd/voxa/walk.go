@@ -0,0 +1,225 @@
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/wirekit/voxa"
+	"github.com/wirekit/voxa/codecs"
+)
+
+const (
+	// maxDumpBytes is the maximum number of bytes of a frame printed by dump.
+	maxDumpBytes = 12
+)
+
+// node is a frame found while walking a payload.
+type node struct {
+	// offset is the position of the frame within the payload.
+	offset int
+	depth  int
+
+	// frame is the frame with it's length prefix, content the frame
+	// without it, and data the content after the Atom and FieldID.
+	frame   []byte
+	content []byte
+	data    []byte
+
+	atom voxa.Atom
+	id   voxa.FieldID
+
+	// name is set for the HeaderCodec entries of a Schema, whose atom
+	// and id are those of the field they name.
+	name string
+}
+
+// container returns true if the data of the node is made of frames.
+func (n node) container() bool {
+	switch n.atom {
+	case voxa.Record, voxa.List, voxa.Map, voxa.Schema, voxa.Union:
+		return n.name == ""
+	}
+	return false
+}
+
+// walk visits every frame within b, and the frames nested within records,
+// lists, maps, schemas and unions, in the order they appear. base is the offset of
+// b within the payload.
+func walk(b []byte, base int, depth int, visit func(node) error) error {
+	for offset := 0; offset < len(b); {
+		frame, content, rest, err := codecs.NextFrame(b[offset:])
+		if err != nil {
+			return fmt.Errorf("offset %d: %s", base+offset, err)
+		}
+
+		n := node{offset: base + offset, depth: depth, frame: frame, content: content}
+		if n.atom, n.id, n.data, err = codecs.ReadHeader(content); err != nil {
+			return fmt.Errorf("offset %d: %s", base+offset, err)
+		}
+
+		if err := visit(n); err != nil {
+			return err
+		}
+
+		dataOffset := n.offset + len(frame) - len(n.data)
+		switch n.atom {
+		case voxa.Record, voxa.List, voxa.Map:
+			if err := walk(n.data, dataOffset, depth+1, visit); err != nil {
+				return err
+			}
+		case voxa.Schema:
+			if err := walkSchema(n.data, dataOffset, depth+1, visit); err != nil {
+				return err
+			}
+		case voxa.Union:
+			_, value, err := codecs.ReadUnion(content)
+			if err != nil {
+				return fmt.Errorf("offset %d: %s", n.offset, err)
+			}
+
+			if err := walk(value, n.offset+len(frame)-len(value), depth+1, visit); err != nil {
+				return err
+			}
+		}
+
+		offset = len(b) - len(rest)
+	}
+
+	return nil
+}
+
+// walkSchema visits the HeaderCodec entries within provided Schema data,
+// then walks the record frame which follows them.
+func walkSchema(b []byte, base int, depth int, visit func(node) error) error {
+	for offset := 0; offset < len(b); {
+		frame, content, rest, err := codecs.NextFrame(b[offset:])
+		if err != nil {
+			return fmt.Errorf("offset %d: %s", base+offset, err)
+		}
+
+		// the last frame is the described record.
+		if len(rest) == 0 {
+			return walk(frame, base+offset, depth, visit)
+		}
+
+		n := node{offset: base + offset, depth: depth, frame: frame, content: content}
+		if n.name, n.id, n.atom, err = (codecs.HeaderCodec{}).BinaryToField(content); err != nil {
+			return fmt.Errorf("offset %d: %s", base+offset, err)
+		}
+
+		if err := visit(n); err != nil {
+			return err
+		}
+
+		offset = len(b) - len(rest)
+	}
+
+	return fmt.Errorf("offset %d: %s", base, codecs.ErrNoDescribedRecord)
+}
+
+// scalarValue decodes the value of provided scalar frame.
+func scalarValue(n node) (interface{}, error) {
+	var value interface{}
+	if err := voxa.Unmarshal(n.frame, &value); err != nil {
+		return nil, fmt.Errorf("offset %d: %s", n.offset, err)
+	}
+	return value, nil
+}
+
+// dump writes an annotated hex tree of the frames within input, showing
+// the offset and bytes of every frame followed by it's Atom, FieldID and
+// value.
+func dump(w io.Writer, input []byte) error {
+	return walk(input, 0, 0, func(n node) error {
+		shown := n.frame
+		if n.container() {
+			shown = n.frame[:len(n.frame)-len(n.data)]
+		}
+
+		var description string
+		switch {
+		case n.name != "":
+			description = fmt.Sprintf("field id=%d atom=%s name=%q", n.id, n.atom, n.name)
+		case n.atom == voxa.Union:
+			typeID, _, err := codecs.ReadUnion(n.content)
+			if err != nil {
+				return err
+			}
+			description = fmt.Sprintf("%s id=%d type=%d", n.atom, n.id, typeID)
+		case n.container():
+			description = fmt.Sprintf("%s id=%d length=%d", n.atom, n.id, len(n.content))
+		case n.atom == voxa.Null:
+			description = fmt.Sprintf("%s id=%d", n.atom, n.id)
+		default:
+			value, err := scalarValue(n)
+			if err != nil {
+				return err
+			}
+			description = fmt.Sprintf("%s id=%d %s", n.atom, n.id, formatValue(value))
+		}
+
+		_, err := fmt.Fprintf(w, "%06d  %-*s  %s%s\n", n.offset, maxDumpBytes*3+2, spaced(shown),
+			strings.Repeat("  ", n.depth), description)
+		return err
+	})
+}
+
+// spaced returns the hex encoding of provided bytes with a space between
+// every byte, eliding bytes after the first maxDumpBytes.
+func spaced(b []byte) string {
+	shown := b
+	if len(b) > maxDumpBytes {
+		shown = b[:maxDumpBytes]
+	}
+
+	hexed := make([]string, len(shown))
+	for i, c := range shown {
+		hexed[i] = hex.EncodeToString([]byte{c})
+	}
+
+	if len(b) > maxDumpBytes {
+		return strings.Join(hexed, " ") + " .."
+	}
+	return strings.Join(hexed, " ")
+}
+
+// formatValue formats a decoded scalar value for dump.
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return fmt.Sprintf("%q", v)
+	case []byte:
+		return fmt.Sprintf("0x%x", v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// validate checks every frame within input is well formed and every
+// scalar can be decoded, writing the number of frames found.
+func validate(w io.Writer, input []byte) error {
+	var frames, top int
+	err := walk(input, 0, 0, func(n node) error {
+		frames++
+		if n.depth == 0 {
+			top++
+		}
+
+		if n.name != "" || n.container() {
+			return nil
+		}
+
+		_, err := scalarValue(n)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "ok: %d frames, %d top level\n", frames, top)
+	return err
+}
@@ -0,0 +1,1131 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"math"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	idTagName       = "id"
+	defaultTagName  = "default"
+	omitEmptyOption = "omitempty"
+	requiredOption  = "required"
+	generatedHeader = "Code generated by voxagen. DO NOT EDIT."
+	voxaPath        = "github.com/wirekit/voxa"
+	codecsPath      = "github.com/wirekit/voxa/codecs"
+)
+
+// class describes how the generator handles a type.
+type class int
+
+const (
+	// classSkip types have no codec and are never encoded.
+	classSkip class = iota
+
+	// classReflect types are encoded and decoded with the reflective codecs.
+	classReflect
+
+	classMarshaler
+	classTime
+	classBool
+	classInt
+	classUint
+	classFloat
+	classComplex
+	classString
+	classSlice
+	classPtr
+)
+
+// loadPackage parses and type checks the package within provided directory,
+// ignoring files previously generated by voxagen.
+func loadPackage(dir string, output string) (*types.Package, error) {
+	buildPkg, err := build.ImportDir(dir, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+
+	var files []*ast.File
+	for _, name := range buildPkg.GoFiles {
+		if output != "" && filepath.Base(output) == name {
+			continue
+		}
+
+		file, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+
+		if isGenerated(file) {
+			continue
+		}
+
+		files = append(files, file)
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no Go files found in %q", dir)
+	}
+
+	config := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	return config.Check(buildPkg.ImportPath, fset, files, nil)
+}
+
+func isGenerated(file *ast.File) bool {
+	for _, group := range file.Comments {
+		if group.Pos() >= file.Package {
+			return false
+		}
+		if strings.TrimSpace(group.Text()) == generatedHeader {
+			return true
+		}
+	}
+	return false
+}
+
+// generator holds the state used to generate the voxa methods of the
+// structs within a package.
+type generator struct {
+	pkg     *types.Package
+	structs map[*types.TypeName]bool
+	imports map[string]string
+	buf     bytes.Buffer
+
+	// usesErr and usesFrame mark if the method being generated uses
+	// it's err and frame variables.
+	usesErr   bool
+	usesFrame bool
+}
+
+// generate returns the formatted source of the voxa methods for the
+// structs with provided names, or all structs with `id` tags if none
+// are provided.
+func generate(pkg *types.Package, names []string) ([]byte, error) {
+	g := &generator{
+		pkg:     pkg,
+		structs: map[*types.TypeName]bool{},
+		imports: map[string]string{voxaPath: "voxa", codecsPath: "codecs"},
+	}
+
+	var targets []*types.TypeName
+	if len(names) == 0 {
+		for _, name := range pkg.Scope().Names() {
+			obj, ok := pkg.Scope().Lookup(name).(*types.TypeName)
+			if !ok {
+				continue
+			}
+
+			if st, ok := obj.Type().Underlying().(*types.Struct); ok && hasIDTags(st) {
+				targets = append(targets, obj)
+			}
+		}
+	} else {
+		for _, name := range names {
+			obj, ok := pkg.Scope().Lookup(strings.TrimSpace(name)).(*types.TypeName)
+			if !ok {
+				return nil, fmt.Errorf("type %q not found in package %q", name, pkg.Name())
+			}
+
+			if _, ok := obj.Type().Underlying().(*types.Struct); !ok {
+				return nil, fmt.Errorf("type %q is not a struct", name)
+			}
+
+			targets = append(targets, obj)
+		}
+	}
+
+	if len(targets) == 0 {
+		return nil, errors.New("no structs with id tags found")
+	}
+
+	for _, target := range targets {
+		g.structs[target] = true
+	}
+
+	var body bytes.Buffer
+	for _, target := range targets {
+		g.buf.Reset()
+		if err := g.generateStruct(target); err != nil {
+			return nil, err
+		}
+		body.Write(g.buf.Bytes())
+	}
+
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "// %s\n\npackage %s\n\nimport (\n", generatedHeader, pkg.Name())
+
+	paths := make([]string, 0, len(g.imports))
+	for path := range g.imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		fmt.Fprintf(&src, "\t%q\n", path)
+	}
+
+	src.WriteString(")\n")
+	src.Write(body.Bytes())
+
+	formatted, err := format.Source(src.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated code: %s", err)
+	}
+	return formatted, nil
+}
+
+func hasIDTags(st *types.Struct) bool {
+	for i := 0; i < st.NumFields(); i++ {
+		if _, ok := reflect.StructTag(st.Tag(i)).Lookup(idTagName); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// field holds a struct field to be generated.
+type field struct {
+	id   uint64
+	name string
+	typ  types.Type
+
+	// expr is the selector of the field from the receiver, such as
+	// `v.Base.Name` for fields promoted from embedded structs, and
+	// embedded the embedded pointers it is selected through.
+	expr     string
+	embedded []embeddedPtr
+
+	omitEmpty  bool
+	required   bool
+	def        string
+	hasDefault bool
+}
+
+// embeddedPtr holds the selector of a embedded pointer to a struct and the
+// struct type it points to.
+type embeddedPtr struct {
+	expr string
+	elem types.Type
+}
+
+// structFields returns the fields of provided struct with their ids and
+// it's voxa.Unknown field, if any, following the same rules as the
+// codecs.RecordCodec.
+func structFields(name string, st *types.Struct) ([]field, *field, error) {
+	fields, unknown, err := collectFields(name, st, "v", nil, map[types.Type]bool{})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fields = dominantFields(fields)
+
+	seen := map[uint64]bool{}
+	for _, f := range fields {
+		if seen[f.id] {
+			return nil, nil, fmt.Errorf("field %q for %q reuses id tag %d", f.name, name, f.id)
+		}
+		seen[f.id] = true
+	}
+
+	return fields, unknown, nil
+}
+
+// collectFields returns the fields of provided struct, selected from provided
+// expression through provided embedded pointers, promoting the fields of
+// embedded structs without a tag unless their type is within visiting.
+func collectFields(name string, st *types.Struct, expr string, embedded []embeddedPtr, visiting map[types.Type]bool) ([]field, *field, error) {
+	var fields []field
+	var unknown *field
+
+	for i := 0; i < st.NumFields(); i++ {
+		v := st.Field(i)
+		tags := reflect.StructTag(st.Tag(i))
+
+		options := strings.Split(tags.Get(idTagName), ",")
+		tag := options[0]
+
+		if v.Embedded() && tag == "" {
+			elem, ptr := v.Type(), false
+			if p, ok := elem.Underlying().(*types.Pointer); ok {
+				elem, ptr = p.Elem(), true
+			}
+
+			if inner, ok := elem.Underlying().(*types.Struct); ok {
+				// pointers to unexported structs can not be allocated when
+				// decoding, hence are skipped with their fields.
+				if visiting[elem] || (ptr && !v.Exported()) {
+					continue
+				}
+
+				innerEmbedded := embedded
+				if ptr {
+					innerEmbedded = append(embedded[:len(embedded):len(embedded)], embeddedPtr{expr: expr + "." + v.Name(), elem: elem})
+				}
+
+				visiting[elem] = true
+				promoted, promotedUnknown, err := collectFields(name, inner, expr+"."+v.Name(), innerEmbedded, visiting)
+				delete(visiting, elem)
+				if err != nil {
+					return nil, nil, err
+				}
+
+				if promotedUnknown != nil {
+					if unknown != nil {
+						return nil, nil, fmt.Errorf("field %q for %q is a second voxa.Unknown field", promotedUnknown.name, name)
+					}
+					unknown = promotedUnknown
+				}
+				fields = append(fields, promoted...)
+				continue
+			}
+		}
+
+		// unexported fields can not be encoded or set by the codecs, hence
+		// are skipped.
+		if !v.Exported() {
+			if tag != "" && tag != "-" {
+				return nil, nil, fmt.Errorf("field %q for %q is unexported and can not have a 'id' tag", v.Name(), name)
+			}
+			continue
+		}
+
+		f := field{name: v.Name(), typ: v.Type(), expr: expr + "." + v.Name(), embedded: embedded}
+
+		// if tag is a dash then skip field, unless it holds unknown fields.
+		if tag == "-" {
+			if isNamed(v.Type(), voxaPath, "Unknown") {
+				if unknown != nil {
+					return nil, nil, fmt.Errorf("field %q for %q is a second voxa.Unknown field", v.Name(), name)
+				}
+				unknown = &f
+			}
+			continue
+		}
+
+		if tag == "" {
+			return nil, nil, fmt.Errorf("field %q for %q requires a 'id' tag", v.Name(), name)
+		}
+
+		id, err := strconv.ParseUint(tag, 10, 32)
+		if err != nil {
+			return nil, nil, fmt.Errorf("field %q for %q has invalid id tag %q: %s", v.Name(), name, tag, err)
+		}
+		f.id = id
+
+		for _, option := range options[1:] {
+			switch option {
+			case omitEmptyOption:
+				f.omitEmpty = true
+			case requiredOption:
+				f.required = true
+			default:
+				return nil, nil, fmt.Errorf("field %q for %q has unknown id tag option %q", v.Name(), name, option)
+			}
+		}
+
+		f.def, f.hasDefault = tags.Lookup(defaultTagName)
+		if f.hasDefault && f.omitEmpty {
+			return nil, nil, fmt.Errorf("field %q for %q can not have both the omitempty option and a default", v.Name(), name)
+		}
+		fields = append(fields, f)
+	}
+
+	return fields, unknown, nil
+}
+
+// dominantFields returns provided fields without those hidden by another
+// field of the same name, as codecs.RecordCodec does.
+func dominantFields(fields []field) []field {
+	depth := func(f field) int {
+		return strings.Count(f.expr, ".")
+	}
+
+	depths := map[string]int{}
+	counts := map[string]int{}
+	for _, f := range fields {
+		d, ok := depths[f.name]
+		switch {
+		case !ok || depth(f) < d:
+			depths[f.name] = depth(f)
+			counts[f.name] = 1
+		case depth(f) == d:
+			counts[f.name]++
+		}
+	}
+
+	dominant := fields[:0]
+	for _, f := range fields {
+		if depth(f) == depths[f.name] && counts[f.name] == 1 {
+			dominant = append(dominant, f)
+		}
+	}
+	return dominant
+}
+
+// withEmbedded wraps provided code of a field selected through embedded
+// pointers so it only runs when they are not nil.
+func withEmbedded(f field, code string) string {
+	for i := len(f.embedded) - 1; i >= 0; i-- {
+		code = fmt.Sprintf("if %s != nil {\n%s}\n", f.embedded[i].expr, code)
+	}
+	return code
+}
+
+// allocEmbedded returns the code which allocates the nil embedded pointers
+// provided field is selected through.
+func (g *generator) allocEmbedded(f field) string {
+	var code bytes.Buffer
+	for _, ptr := range f.embedded {
+		fmt.Fprintf(&code, "if %s == nil {\n%s = new(%s)\n}\n", ptr.expr, ptr.expr, g.typeString(ptr.elem))
+	}
+	return code.String()
+}
+
+func (g *generator) generateStruct(obj *types.TypeName) error {
+	fields, unknown, err := structFields(obj.Name(), obj.Type().Underlying().(*types.Struct))
+	if err != nil {
+		return err
+	}
+
+	// MarshalVoxa
+	var encoders bytes.Buffer
+	g.usesErr = false
+	for _, f := range fields {
+		expr, id := f.expr, strconv.FormatUint(f.id, 10)
+
+		code := g.encodeField(expr, f.typ, id, 0)
+		if f.omitEmpty && code != "" {
+			// nil pointers are omitted, hence need no Null frame.
+			if ptr, ok := f.typ.Underlying().(*types.Pointer); ok && g.exact(f.typ) {
+				code = g.encodeExact(deref(g.classify(ptr.Elem()), expr), ptr.Elem(), id, 0)
+			}
+			code = fmt.Sprintf("if %s {\n%s}\n", g.nonEmpty(expr, f.typ), code)
+		}
+		if code != "" {
+			// fields within nil embedded pointers are left out.
+			encoders.WriteString(withEmbedded(f, code))
+		}
+	}
+	if unknown != nil {
+		encoders.WriteString(withEmbedded(*unknown, fmt.Sprintf("c = append(c, %s...)\n", unknown.expr)))
+	}
+
+	fmt.Fprintf(&g.buf, "\n// MarshalVoxa implements the voxa.Marshaler interface.\n")
+	fmt.Fprintf(&g.buf, "func (v %s) MarshalVoxa(id voxa.FieldID, c []byte) ([]byte, error) {\n", obj.Name())
+	if g.usesErr {
+		g.buf.WriteString("var err error\n")
+	}
+	g.buf.WriteString("c, start := codecs.ReserveFrame(c)\n")
+	g.buf.WriteString("c = codecs.AppendHeader(c, voxa.Record, id)\n")
+	g.buf.Write(encoders.Bytes())
+	g.buf.WriteString("return codecs.CloseFrame(c, start), nil\n}\n")
+
+	// UnmarshalVoxa
+	var defaults bytes.Buffer
+	for _, f := range fields {
+		if !f.hasDefault {
+			continue
+		}
+
+		code, err := g.defaultValue(f.expr, f.typ, f.def)
+		if err != nil {
+			return fmt.Errorf("field %q for %q has invalid default: %s", f.name, obj.Name(), err)
+		}
+		defaults.WriteString(g.allocEmbedded(f) + code)
+	}
+
+	var decoders, seen, missing bytes.Buffer
+	g.usesFrame = false
+	for _, f := range fields {
+		code := g.decodeField(f.expr, f.typ, "frame", "content", 0)
+		if code == "" {
+			continue
+		}
+		code = g.allocEmbedded(f) + code
+
+		// required fields are marked as seen once decoded.
+		if f.required {
+			fmt.Fprintf(&seen, "var seen%d bool\n", f.id)
+			fmt.Fprintf(&missing, "if !seen%d {\nmissing = append(missing, %d)\n}\n", f.id, f.id)
+			code += fmt.Sprintf("seen%d = true\n", f.id)
+		}
+		fmt.Fprintf(&decoders, "case %d:\n%s", f.id, code)
+	}
+	if unknown != nil {
+		g.usesFrame = true
+		fmt.Fprintf(&decoders, "default:\n%s%s = append(%s, frame...)\n", g.allocEmbedded(*unknown), unknown.expr, unknown.expr)
+	} else {
+		decoders.WriteString("default:\nif limits.DisallowUnknownFields {\nunknown = append(unknown, id)\n}\n")
+	}
+
+	fmt.Fprintf(&g.buf, "\n// UnmarshalVoxa implements the voxa.Unmarshaler interface.\n")
+	fmt.Fprintf(&g.buf, "func (v *%s) UnmarshalVoxa(b []byte) error {\n", obj.Name())
+	g.buf.WriteString("return v.UnmarshalVoxaLimits(b, voxa.DefaultLimits())\n}\n")
+
+	fmt.Fprintf(&g.buf, "\n// UnmarshalVoxaLimits implements the voxa.LimitedUnmarshaler interface.\n")
+	fmt.Fprintf(&g.buf, "func (v *%s) UnmarshalVoxaLimits(b []byte, limits voxa.Limits) error {\n", obj.Name())
+	g.buf.WriteString(`limits, err := codecs.EnterRecord(limits)
+if err != nil {
+	return err
+}
+
+_, content, _, err := codecs.NextFrame(b)
+if err != nil {
+	return err
+}
+
+atom, _, fields, err := codecs.ReadHeader(content)
+if err != nil {
+	return err
+}
+
+if atom != voxa.Record {
+	return codecs.ErrNotRecord
+}
+
+`)
+	if defaults.Len() > 0 {
+		g.buf.WriteString("// defaults are set first, hence only fields missing from the record\n")
+		g.buf.WriteString("// keep them.\n")
+		g.buf.Write(defaults.Bytes())
+		g.buf.WriteString("\n")
+	}
+	if seen.Len() > 0 {
+		g.buf.Write(seen.Bytes())
+		g.buf.WriteString("\n")
+	}
+	if unknown != nil {
+		g.buf.WriteString("// unknown frames are only those of the decoded record.\n")
+		fmt.Fprintf(&g.buf, "%s%s = nil\n\n", g.allocEmbedded(*unknown), unknown.expr)
+	} else {
+		g.buf.WriteString("var unknown []voxa.FieldID\n")
+	}
+	g.buf.WriteString("for len(fields) > 0 {\n")
+	if g.usesFrame {
+		g.buf.WriteString("frame, content, rest, err := codecs.NextFrame(fields)\n")
+	} else {
+		g.buf.WriteString("_, content, rest, err := codecs.NextFrame(fields)\n")
+	}
+	g.buf.WriteString(`if err != nil {
+	return err
+}
+
+_, id, _, err := codecs.ReadHeader(content)
+if err != nil {
+	return err
+}
+
+switch id {
+`)
+	g.buf.Write(decoders.Bytes())
+	g.buf.WriteString("}\n\nfields = rest\n}\n\n")
+	switch {
+	case missing.Len() > 0 && unknown == nil:
+		g.buf.WriteString("var missing []voxa.FieldID\n")
+		g.buf.Write(missing.Bytes())
+		g.buf.WriteString("if missing != nil || unknown != nil {\nreturn &voxa.FieldError{Missing: missing, Unknown: unknown}\n}\n\n")
+	case missing.Len() > 0:
+		g.buf.WriteString("var missing []voxa.FieldID\n")
+		g.buf.Write(missing.Bytes())
+		g.buf.WriteString("if missing != nil {\nreturn &voxa.FieldError{Missing: missing}\n}\n\n")
+	case unknown == nil:
+		g.buf.WriteString("if unknown != nil {\nreturn &voxa.FieldError{Unknown: unknown}\n}\n\n")
+	}
+	g.buf.WriteString("return nil\n}\n")
+	return nil
+}
+
+// classify returns the class of provided type, matching the order in which
+// the codecs choose the encoding of a type.
+func (g *generator) classify(t types.Type) class {
+	// nil pointers are skipped before any of their methods are used.
+	if _, ok := t.Underlying().(*types.Pointer); ok {
+		return classPtr
+	}
+
+	if named, ok := t.(*types.Named); ok && g.structs[named.Obj()] {
+		return classMarshaler
+	}
+
+	if hasMethod(t, "MarshalVoxa") {
+		if hasMethod(t, "UnmarshalVoxa") {
+			return classMarshaler
+		}
+		return classReflect
+	}
+
+	if isNamed(t, "time", "Time") {
+		return classTime
+	}
+
+	if hasMethod(t, "MarshalBinary") || hasMethod(t, "MarshalText") {
+		return classReflect
+	}
+
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case u.Kind() == types.Bool:
+			return classBool
+		case u.Kind() == types.Uintptr:
+			return classSkip
+		case u.Info()&types.IsInteger != 0 && u.Info()&types.IsUnsigned != 0:
+			return classUint
+		case u.Info()&types.IsInteger != 0:
+			return classInt
+		case u.Info()&types.IsFloat != 0:
+			return classFloat
+		case u.Info()&types.IsComplex != 0:
+			return classComplex
+		case u.Info()&types.IsString != 0:
+			return classString
+		}
+	case *types.Slice:
+		return classSlice
+	case *types.Struct, *types.Map, *types.Interface, *types.Array:
+		return classReflect
+	}
+
+	return classSkip
+}
+
+// exact reports whether the generator can encode provided type without
+// reflection, writing the exact bytes of the codecs.
+func (g *generator) exact(t types.Type) bool {
+	switch g.classify(t) {
+	case classSkip, classReflect:
+		return false
+	case classSlice:
+		return g.exact(t.Underlying().(*types.Slice).Elem())
+	case classPtr:
+		return g.exact(t.Underlying().(*types.Pointer).Elem())
+	}
+	return true
+}
+
+func hasMethod(t types.Type, name string) bool {
+	if _, ok := t.Underlying().(*types.Pointer); !ok {
+		t = types.NewPointer(t)
+	}
+
+	obj, _, _ := types.LookupFieldOrMethod(t, true, nil, name)
+	_, ok := obj.(*types.Func)
+	return ok
+}
+
+func (g *generator) typeString(t types.Type) string {
+	return types.TypeString(t, func(p *types.Package) string {
+		if p == g.pkg {
+			return ""
+		}
+		g.imports[p.Path()] = p.Name()
+		return p.Name()
+	})
+}
+
+// encodeField returns the code which appends the frame of provided
+// expression into `c`.
+func (g *generator) encodeField(expr string, t types.Type, id string, depth int) string {
+	switch g.classify(t) {
+	case classSkip:
+		return ""
+	}
+
+	if !g.exact(t) {
+		g.usesErr = true
+
+		// interfaces are passed by pointer, as values of a registered type
+		// are only encoded as a Union when held by an interface.
+		if _, ok := t.Underlying().(*types.Interface); ok {
+			expr = "&" + expr
+		}
+		return fmt.Sprintf("if c, err = codecs.AppendValue(%s, %s, c); err != nil {\nreturn nil, err\n}\n", expr, id)
+	}
+
+	return g.encodeExact(expr, t, id, depth)
+}
+
+func (g *generator) encodeExact(expr string, t types.Type, id string, depth int) string {
+	g.usesErr = true
+
+	switch g.classify(t) {
+	case classMarshaler:
+		return fmt.Sprintf("if c, err = %s.MarshalVoxa(%s, c); err != nil {\nreturn nil, err\n}\n", expr, id)
+	case classTime:
+		return g.appendFrame("TimeCodec", expr, id)
+	case classBool:
+		return g.appendFrame("BooleanCodec", convertTo(t, "bool", expr), id)
+	case classString:
+		return g.appendFrame("TextCodec", convertTo(t, "string", expr), id)
+	case classInt, classUint:
+		return g.appendFrame("IntCodec", convertTo(t, basicName(t), expr), id)
+	case classFloat:
+		return g.appendFrame("FloatCodec", convertTo(t, basicName(t), expr), id)
+	case classComplex:
+		return g.appendFrame("ComplexCodec", convertTo(t, basicName(t), expr), id)
+	case classPtr:
+		elem := t.Underlying().(*types.Pointer).Elem()
+		return fmt.Sprintf("if %s != nil {\n%s} else {\nc = codecs.AppendNull(c, %s)\n}\n", expr,
+			g.encodeExact(deref(g.classify(elem), expr), elem, id, depth), id)
+	case classSlice:
+		elem := t.Underlying().(*types.Slice).Elem()
+
+		// byte slices are encoded as a Bytes frame, as the codecs do.
+		if isByte(elem) {
+			if types.Identical(elem, types.Typ[types.Byte]) {
+				value := expr
+				if !types.Identical(t, types.NewSlice(elem)) {
+					value = fmt.Sprintf("[]byte(%s)", expr)
+				}
+				return fmt.Sprintf("if %s == nil {\nc = codecs.AppendNull(c, %s)\n} else {\n%s}\n", expr, id,
+					g.appendFrame("BytesCodec", value, id))
+			}
+			return fmt.Sprintf("if c, err = codecs.AppendValue(%s, %s, c); err != nil {\nreturn nil, err\n}\n", expr, id)
+		}
+
+		if atom, ok := g.packedAtom(elem); ok {
+			return g.encodePacked(expr, elem, atom, id, depth)
+		}
+
+		index, item := fmt.Sprintf("i%d", depth), fmt.Sprintf("item%d", depth)
+		mark := fmt.Sprintf("mark%d", depth)
+
+		var code bytes.Buffer
+		fmt.Fprintf(&code, "if %s == nil {\nc = codecs.AppendNull(c, %s)\n} else {\nvar %s int\n", expr, id, mark)
+		fmt.Fprintf(&code, "c, %s = codecs.ReserveFrame(c)\n", mark)
+		fmt.Fprintf(&code, "c = codecs.AppendHeader(c, voxa.List, %s)\n", id)
+		fmt.Fprintf(&code, "for %s, %s := range %s {\n", index, item, expr)
+		code.WriteString(g.encodeExact(item, elem, fmt.Sprintf("voxa.FieldID(%s)", index), depth+1))
+		code.WriteString("}\n")
+		fmt.Fprintf(&code, "c = codecs.CloseFrame(c, %s)\n}\n", mark)
+		return code.String()
+	}
+
+	return ""
+}
+
+// encodePacked returns the code which appends provided slice expression,
+// whose elements are of provided element Atom, as a Packed frame.
+func (g *generator) encodePacked(expr string, elem types.Type, atom string, id string, depth int) string {
+	item, mark := fmt.Sprintf("item%d", depth), fmt.Sprintf("mark%d", depth)
+
+	var value string
+	switch g.classify(elem) {
+	case classBool:
+		value = fmt.Sprintf("codecs.AppendPackedBool(c, %s)", convertTo(elem, "bool", item))
+	case classInt:
+		value = fmt.Sprintf("codecs.AppendPackedInt(c, %s, %s)", atom, convertTo(elem, "int64", item))
+	case classUint:
+		value = fmt.Sprintf("codecs.AppendPackedUint(c, %s, %s)", atom, convertTo(elem, "uint64", item))
+	case classFloat:
+		value = fmt.Sprintf("codecs.AppendPackedFloat(c, %s, %s)", atom, convertTo(elem, "float64", item))
+	case classComplex:
+		value = fmt.Sprintf("codecs.AppendPackedComplex(c, %s, %s)", atom, convertTo(elem, "complex128", item))
+	}
+
+	var code bytes.Buffer
+	fmt.Fprintf(&code, "if %s == nil {\nc = codecs.AppendNull(c, %s)\n} else {\nvar %s int\n", expr, id, mark)
+	fmt.Fprintf(&code, "c, %s = codecs.ReservePacked(c, %s, %s, len(%s))\n", mark, atom, id, expr)
+	fmt.Fprintf(&code, "for _, %s := range %s {\nc = %s\n}\n", item, expr, value)
+	fmt.Fprintf(&code, "c = codecs.CloseFrame(c, %s)\n}\n", mark)
+	return code.String()
+}
+
+// isByte reports whether provided slice element type is a byte, or a named
+// type of one.
+func isByte(elem types.Type) bool {
+	b, ok := elem.Underlying().(*types.Basic)
+	return ok && b.Kind() == types.Uint8
+}
+
+// packedAtom returns the element Atom of a packed slice with provided
+// element type, which must be a number or boolean without methods of it's
+// own encoding.
+func (g *generator) packedAtom(elem types.Type) (string, bool) {
+	switch g.classify(elem) {
+	case classBool:
+		return "voxa.Boolean", true
+	case classInt, classUint, classFloat, classComplex:
+	default:
+		return "", false
+	}
+
+	switch elem.Underlying().(*types.Basic).Kind() {
+	case types.Int:
+		return "voxa.SInt", true
+	case types.Int8:
+		return "voxa.Int8", true
+	case types.Int16:
+		return "voxa.Int16", true
+	case types.Int32:
+		return "voxa.SInt32", true
+	case types.Int64:
+		return "voxa.SInt64", true
+	case types.Uint:
+		return "voxa.UInt", true
+	case types.Uint8:
+		return "voxa.UInt8", true
+	case types.Uint16:
+		return "voxa.UInt16", true
+	case types.Uint32:
+		return "voxa.UInt32", true
+	case types.Uint64:
+		return "voxa.UInt64", true
+	case types.Float32:
+		return "voxa.Float32", true
+	case types.Float64:
+		return "voxa.Float64", true
+	case types.Complex64:
+		return "voxa.Complex64", true
+	case types.Complex128:
+		return "voxa.Complex128", true
+	}
+	return "", false
+}
+
+func (g *generator) appendFrame(codec string, expr string, id string) string {
+	return fmt.Sprintf("if c, err = codecs.AppendFrame(codecs.%s{}, %s, %s, c); err != nil {\nreturn nil, err\n}\n", codec, expr, id)
+}
+
+// decodeField returns the code which decodes the frame in provided frame
+// and content variables into provided target expression.
+func (g *generator) decodeField(target string, t types.Type, frame string, content string, depth int) string {
+	switch g.classify(t) {
+	case classSkip:
+		return ""
+	}
+
+	if !g.exact(t) {
+		g.useFrame(depth)
+		return fmt.Sprintf("if err := codecs.DecodeValueLimits(%s, &%s, %s); err != nil {\nreturn err\n}\n", frame, target, limitsVar(depth))
+	}
+
+	return g.decodeNullable(target, t, frame, content, depth)
+}
+
+// decodeNullable returns the code of decodeExact preceded by a check which
+// sets provided target to it's zero value for a Null frame, as the codecs
+// do. Pointers and slices check for Null frames on their own.
+func (g *generator) decodeNullable(target string, t types.Type, frame string, content string, depth int) string {
+	code := g.decodeExact(target, t, frame, content, depth)
+
+	zero, ok := g.zero(t)
+	if !ok {
+		return code
+	}
+	return fmt.Sprintf("if codecs.IsNull(%s) {\n%s = %s\n} else {\n%s}\n", content, target, zero, code)
+}
+
+// zero returns the zero value of provided type, for the classes whose
+// decoders do not accept a Null frame.
+func (g *generator) zero(t types.Type) (string, bool) {
+	switch g.classify(t) {
+	case classBool:
+		return "false", true
+	case classString:
+		return `""`, true
+	case classInt, classUint, classFloat, classComplex:
+		return "0", true
+	case classTime:
+		return g.typeString(t) + "{}", true
+	case classMarshaler:
+		if _, ok := t.Underlying().(*types.Struct); ok {
+			return g.typeString(t) + "{}", true
+		}
+		return "*new(" + g.typeString(t) + ")", true
+	}
+	return "", false
+}
+
+func (g *generator) decodeExact(target string, t types.Type, frame string, content string, depth int) string {
+	switch g.classify(t) {
+	case classMarshaler:
+		g.useFrame(depth)
+		if g.limited(t) {
+			return fmt.Sprintf("if err := %s.UnmarshalVoxaLimits(%s, %s); err != nil {\nreturn err\n}\n", target, frame, limitsVar(depth))
+		}
+		return fmt.Sprintf("if err := %s.UnmarshalVoxa(%s); err != nil {\nreturn err\n}\n", target, frame)
+	case classTime:
+		return g.frameTo("FrameToTime", target, "value", content)
+	case classBool:
+		return g.frameTo("FrameToBool", target, g.convertFrom(t, "bool", "value"), content)
+	case classString:
+		return g.frameTo("FrameToText", target, g.convertFrom(t, "string", "value"), content)
+	case classInt:
+		return g.frameTo("FrameToInt", target, g.convertFrom(t, "int64", "value"), sized(content, t))
+	case classUint:
+		return g.frameTo("FrameToUint", target, g.convertFrom(t, "uint64", "value"), sized(content, t))
+	case classFloat:
+		return g.frameTo("FrameToFloat", target, g.convertFrom(t, "float64", "value"), sized(content, t))
+	case classComplex:
+		return g.frameTo("FrameToComplex128", target, g.convertFrom(t, "complex128", "value"), content)
+	case classPtr:
+		elem := t.Underlying().(*types.Pointer).Elem()
+		return fmt.Sprintf("if codecs.IsNull(%s) {\n%s = nil\n} else {\nif %s == nil {\n%s = new(%s)\n}\n%s}\n",
+			content, target, target, target, g.typeString(elem),
+			g.decodeExact(deref(g.classify(elem), target), elem, frame, content, depth))
+	case classSlice:
+		elem := t.Underlying().(*types.Slice).Elem()
+
+		// bytes and packed values are decoded with the reflective codecs,
+		// which also decode the lists written before they were packed.
+		if _, ok := g.packedAtom(elem); ok || isByte(elem) {
+			g.useFrame(depth)
+			return fmt.Sprintf("if err := codecs.DecodeValueLimits(%s, &%s, %s); err != nil {\nreturn err\n}\n", frame, target, limitsVar(depth))
+		}
+
+		items, list, item := fmt.Sprintf("items%d", depth), fmt.Sprintf("list%d", depth), fmt.Sprintf("item%d", depth)
+		itemFrame, itemContent, rest := fmt.Sprintf("frame%d", depth), fmt.Sprintf("content%d", depth), fmt.Sprintf("rest%d", depth)
+		count, itemLimits := fmt.Sprintf("count%d", depth), limitsVar(depth+1)
+
+		inner := g.decodeNullable(item, elem, itemFrame, itemContent, depth+1)
+		frameVar, contentVar, limitsName := itemFrame, itemContent, itemLimits
+		if !strings.Contains(inner, itemFrame) {
+			frameVar = "_"
+		}
+		if !strings.Contains(inner, itemContent) {
+			contentVar = "_"
+		}
+		if !strings.Contains(inner, itemLimits) {
+			limitsName = "_"
+		}
+
+		var code bytes.Buffer
+		fmt.Fprintf(&code, "atom, _, %s, err := codecs.ReadHeader(%s)\n", items, content)
+		code.WriteString("if err != nil {\nreturn err\n}\n\n")
+		code.WriteString("if atom != voxa.List && atom != voxa.Null {\nreturn codecs.ErrNotList\n}\n\n")
+		// empty lists decode into an empty slice rather than nil, as they do
+		// with the reflective codecs.
+		fmt.Fprintf(&code, "var %s %s\n", list, g.typeString(t))
+		code.WriteString("if atom == voxa.List {\n")
+		fmt.Fprintf(&code, "%s, %s, err := codecs.EnterList(%s, %s)\n", limitsName, count, limitsVar(depth), items)
+		code.WriteString("if err != nil {\nreturn err\n}\n\n")
+		fmt.Fprintf(&code, "%s = make(%s, 0, %s)\n", list, g.typeString(t), count)
+		fmt.Fprintf(&code, "for len(%s) > 0 {\n", items)
+		fmt.Fprintf(&code, "%s, %s, %s, err := codecs.NextFrame(%s)\n", frameVar, contentVar, rest, items)
+		code.WriteString("if err != nil {\nreturn err\n}\n\n")
+		fmt.Fprintf(&code, "var %s %s\n", item, g.typeString(elem))
+		code.WriteString(inner)
+		fmt.Fprintf(&code, "%s = append(%s, %s)\n", list, list, item)
+		fmt.Fprintf(&code, "%s = %s\n", items, rest)
+		code.WriteString("}\n}\n\n")
+		fmt.Fprintf(&code, "%s = %s\n", target, list)
+		return code.String()
+	}
+
+	return ""
+}
+
+// limited returns true if provided Unmarshaler type receives the limits of
+// the decode, as the structs generated alongside it do.
+func (g *generator) limited(t types.Type) bool {
+	if named, ok := t.(*types.Named); ok && g.structs[named.Obj()] {
+		return true
+	}
+	return hasMethod(t, "UnmarshalVoxaLimits")
+}
+
+// limitsVar returns the variable holding the limits of the frames decoded
+// at provided depth.
+func limitsVar(depth int) string {
+	if depth == 0 {
+		return "limits"
+	}
+	return fmt.Sprintf("limits%d", depth-1)
+}
+
+func (g *generator) useFrame(depth int) {
+	if depth == 0 {
+		g.usesFrame = true
+	}
+}
+
+// frameTo returns the code which decodes a scalar frame content with the
+// provided codecs function, assigning the value expression to target.
+func (g *generator) frameTo(fn string, target string, value string, content string) string {
+	return fmt.Sprintf("value, err := codecs.%s(%s)\nif err != nil {\nreturn err\n}\n%s = %s\n", fn, content, target, value)
+}
+
+// convertFrom returns provided expression converted from the basic type with
+// provided name into type t, unless t is that basic type.
+func (g *generator) convertFrom(t types.Type, basic string, expr string) string {
+	if b, ok := t.(*types.Basic); ok && b.Name() == basic {
+		return expr
+	}
+	return fmt.Sprintf("%s(%s)", g.typeString(t), expr)
+}
+
+// convertTo returns provided expression of type t converted into the basic
+// type with provided name, unless t is that basic type.
+func convertTo(t types.Type, basic string, expr string) string {
+	if b, ok := t.(*types.Basic); ok && b.Name() == basic {
+		return expr
+	}
+	return fmt.Sprintf("%s(%s)", basic, expr)
+}
+
+// sized returns the arguments decoding provided frame content into a number
+// of the bit size of basic type t, where 0 is the size of an int or uint.
+func sized(content string, t types.Type) string {
+	size := 64
+	switch t.Underlying().(*types.Basic).Kind() {
+	case types.Int, types.Uint:
+		size = 0
+	case types.Int8, types.Uint8:
+		size = 8
+	case types.Int16, types.Uint16:
+		size = 16
+	case types.Int32, types.Uint32, types.Float32:
+		size = 32
+	}
+	return fmt.Sprintf("%s, %d", content, size)
+}
+
+// deref returns the expression which dereferences provided pointer
+// expression, which is not needed to call the methods of a Marshaler.
+func deref(elem class, expr string) string {
+	if elem == classMarshaler {
+		return expr
+	}
+	return "(*" + expr + ")"
+}
+
+// isNamed returns true if provided type is the named type with provided
+// package path and name.
+func isNamed(t types.Type, path string, name string) bool {
+	named, ok := t.(*types.Named)
+	return ok && named.Obj().Pkg() != nil && named.Obj().Pkg().Path() == path && named.Obj().Name() == name
+}
+
+// nonEmpty returns the condition which is true when provided expression
+// does not hold an empty value, as decided by codecs.IsEmpty.
+func (g *generator) nonEmpty(expr string, t types.Type) string {
+	switch g.classify(t) {
+	case classBool:
+		return expr
+	case classInt, classUint, classFloat, classComplex:
+		return expr + " != 0"
+	case classString:
+		return expr + ` != ""`
+	case classTime:
+		return "!" + expr + ".IsZero()"
+	case classSlice:
+		return "len(" + expr + ") != 0"
+	case classPtr:
+		return expr + " != nil"
+	}
+
+	switch t.Underlying().(type) {
+	case *types.Map:
+		return "len(" + expr + ") != 0"
+	case *types.Interface:
+		return expr + " != nil"
+	}
+	return "!codecs.IsEmpty(" + expr + ")"
+}
+
+// defaultValue returns the code which sets provided target to the value of
+// provided `default` tag, using a literal for booleans, numbers and strings
+// and codecs.SetDefault for all other types.
+func (g *generator) defaultValue(target string, t types.Type, def string) (string, error) {
+	var literal string
+	switch class := g.classify(t); {
+	case isNamed(t, "time", "Duration"):
+		// durations are parsed by codecs.SetDefault.
+	case class == classBool:
+		value, err := strconv.ParseBool(def)
+		if err != nil {
+			return "", err
+		}
+		literal = strconv.FormatBool(value)
+	case class == classInt:
+		value, err := strconv.ParseInt(def, 10, bitSize(t))
+		if err != nil {
+			return "", err
+		}
+		literal = strconv.FormatInt(value, 10)
+	case class == classUint:
+		value, err := strconv.ParseUint(def, 10, bitSize(t))
+		if err != nil {
+			return "", err
+		}
+		literal = strconv.FormatUint(value, 10)
+	case class == classFloat:
+		value, err := strconv.ParseFloat(def, bitSize(t))
+		if err != nil {
+			return "", err
+		}
+		if !math.IsInf(value, 0) && !math.IsNaN(value) {
+			literal = strconv.FormatFloat(value, 'g', -1, 64)
+		}
+	case class == classString:
+		literal = strconv.Quote(def)
+	}
+
+	if literal != "" {
+		return fmt.Sprintf("%s = %s\n", target, literal), nil
+	}
+	return fmt.Sprintf("if err := codecs.SetDefault(&%s, %q); err != nil {\nreturn err\n}\n", target, def), nil
+}
+
+// bitSize returns the size in bits of the basic number type underlying
+// provided type. Platform sized integers are taken as 32 bits, so that
+// literals generated for them compile on 32 bit platforms as well.
+func bitSize(t types.Type) int {
+	name := basicName(t)
+	if bits, err := strconv.Atoi(strings.TrimLeft(name, "intufloa")); err == nil {
+		return bits
+	}
+	return 32
+}
+
+// basicName returns the name of the basic type underlying provided type.
+func basicName(t types.Type) string {
+	switch t.Underlying().(*types.Basic).Kind() {
+	case types.Int:
+		return "int"
+	case types.Int8:
+		return "int8"
+	case types.Int16:
+		return "int16"
+	case types.Int32:
+		return "int32"
+	case types.Int64:
+		return "int64"
+	case types.Uint:
+		return "uint"
+	case types.Uint8:
+		return "uint8"
+	case types.Uint16:
+		return "uint16"
+	case types.Uint32:
+		return "uint32"
+	case types.Uint64:
+		return "uint64"
+	case types.Float32:
+		return "float32"
+	case types.Float64:
+		return "float64"
+	case types.Complex64:
+		return "complex64"
+	case types.Complex128:
+		return "complex128"
+	}
+	return t.Underlying().String()
+}
@@ -0,0 +1,64 @@
+// Package fixtures holds the structs used to test the code generated by
+// voxagen against the reflective codecs.
+package fixtures
+
+import (
+	"time"
+
+	"github.com/wirekit/voxa"
+)
+
+//go:generate go run ../.. .
+
+// Address is a struct nested within Person.
+type Address struct {
+	Value string `id:"1"`
+}
+
+// Entity is embedded within Person, hence it's fields are promoted into the
+// record of Person.
+type Entity struct {
+	ID      uint32 `id:"20"`
+	Version int    `id:"21" default:"1"`
+}
+
+// Stamp is embedded within Person as a pointer.
+type Stamp struct {
+	Author string `id:"22"`
+}
+
+// Person is a struct with fields of all kinds supported by voxagen.
+type Person struct {
+	Age        int          `id:"1"`
+	Name       string       `id:"2,required"`
+	Address    string       `id:"3"`
+	OtherNames []string     `id:"4"`
+	Addresses  []Address    `id:"5"`
+	Home       *Address     `id:"6"`
+	Date       time.Time    `id:"7"`
+	Score      float64      `id:"8"`
+	Active     bool         `id:"9"`
+	Matrix     [][]int64    `id:"10"`
+	Counts     []uint16     `id:"11"`
+	Note       interface{}  `id:"12"`
+	Skipped    string       `id:"-"`
+	Extra      voxa.Unknown `id:"-"`
+
+	Nickname string        `id:"13,omitempty"`
+	Retries  int           `id:"14" default:"3"`
+	Timeout  time.Duration `id:"15" default:"1.5s"`
+	Ratio    *float32      `id:"16" default:"0.5"`
+
+	Digest   [4]byte    `id:"17"`
+	Position [3]float64 `id:"18"`
+
+	Phase   complex128  `id:"19"`
+	Samples []complex64 `id:"23"`
+	Expires *time.Time  `id:"24"`
+	Raw     []byte      `id:"25"`
+
+	Entity
+	*Stamp
+
+	secret string
+}
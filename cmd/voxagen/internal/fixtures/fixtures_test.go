@@ -0,0 +1,554 @@
+package fixtures_test
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/influx6/faux/tests"
+	"github.com/wirekit/voxa"
+	"github.com/wirekit/voxa/cmd/voxagen/internal/fixtures"
+	"github.com/wirekit/voxa/codecs"
+)
+
+// address and person mirror the fixtures without the generated methods,
+// hence are encoded by the reflective codecs.
+type address struct {
+	Value string `id:"1"`
+}
+
+type entity struct {
+	ID      uint32 `id:"20"`
+	Version int    `id:"21" default:"1"`
+}
+
+// Stamp is exported, as embedded pointers to unexported structs are skipped.
+type Stamp struct {
+	Author string `id:"22"`
+}
+
+type person struct {
+	Age        int          `id:"1"`
+	Name       string       `id:"2,required"`
+	Address    string       `id:"3"`
+	OtherNames []string     `id:"4"`
+	Addresses  []address    `id:"5"`
+	Home       *address     `id:"6"`
+	Date       time.Time    `id:"7"`
+	Score      float64      `id:"8"`
+	Active     bool         `id:"9"`
+	Matrix     [][]int64    `id:"10"`
+	Counts     []uint16     `id:"11"`
+	Note       interface{}  `id:"12"`
+	Skipped    string       `id:"-"`
+	Extra      voxa.Unknown `id:"-"`
+
+	Nickname string        `id:"13,omitempty"`
+	Retries  int           `id:"14" default:"3"`
+	Timeout  time.Duration `id:"15" default:"1.5s"`
+	Ratio    *float32      `id:"16" default:"0.5"`
+
+	Digest   [4]byte    `id:"17"`
+	Position [3]float64 `id:"18"`
+
+	Phase   complex128  `id:"19"`
+	Samples []complex64 `id:"23"`
+	Expires *time.Time  `id:"24"`
+	Raw     []byte      `id:"25"`
+
+	entity
+	*Stamp
+
+	secret string
+}
+
+var (
+	date  = time.Date(2018, time.March, 4, 10, 20, 30, 0, time.UTC)
+	ratio = float32(0.25)
+)
+
+var generated = fixtures.Person{
+	Age:        -32,
+	Name:       "Alex Woodpecker",
+	Address:    "12 Ringwood Street",
+	OtherNames: []string{"Al", "Woody"},
+	Addresses:  []fixtures.Address{{Value: "Lagos"}, {Value: "Berlin"}},
+	Home:       &fixtures.Address{Value: "Dublin"},
+	Date:       date,
+	Score:      98.5,
+	Active:     true,
+	Matrix:     [][]int64{{1, -2}, {300, -40000}},
+	Counts:     []uint16{1, 65535},
+	Note:       "remember the milk",
+	Nickname:   "Woody",
+	Retries:    5,
+	Timeout:    2 * time.Second,
+	Ratio:      &ratio,
+	Digest:     [4]byte{0xde, 0xad, 0xbe, 0xef},
+	Position:   [3]float64{1.5, -2, 300},
+	Phase:      complex(0.5, -1.5),
+	Samples:    []complex64{1 + 2i, -3.25i},
+	Expires:    &date,
+	Raw:        []byte("raw"),
+	Entity:     fixtures.Entity{ID: 7, Version: 2},
+	Stamp:      &fixtures.Stamp{Author: "ana"},
+}
+
+var reflective = person{
+	Age:        -32,
+	Name:       "Alex Woodpecker",
+	Address:    "12 Ringwood Street",
+	OtherNames: []string{"Al", "Woody"},
+	Addresses:  []address{{Value: "Lagos"}, {Value: "Berlin"}},
+	Home:       &address{Value: "Dublin"},
+	Date:       date,
+	Score:      98.5,
+	Active:     true,
+	Matrix:     [][]int64{{1, -2}, {300, -40000}},
+	Counts:     []uint16{1, 65535},
+	Note:       "remember the milk",
+	Nickname:   "Woody",
+	Retries:    5,
+	Timeout:    2 * time.Second,
+	Ratio:      &ratio,
+	Digest:     [4]byte{0xde, 0xad, 0xbe, 0xef},
+	Position:   [3]float64{1.5, -2, 300},
+	Phase:      complex(0.5, -1.5),
+	Samples:    []complex64{1 + 2i, -3.25i},
+	Expires:    &date,
+	Raw:        []byte("raw"),
+	entity:     entity{ID: 7, Version: 2},
+	Stamp:      &Stamp{Author: "ana"},
+}
+
+func TestGenerated_MarshalVoxa(t *testing.T) {
+	encoded, err := generated.MarshalVoxa(0, nil)
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded value with generated method")
+	}
+	tests.Passed("Should have successfully encoded value with generated method")
+
+	expected, err := codecs.RecordCodec{}.NativeToBinary(reflective, nil)
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded value with record codec")
+	}
+	tests.Passed("Should have successfully encoded value with record codec")
+
+	if !bytes.Equal(encoded, expected) {
+		tests.Info("Generated: %#v", encoded)
+		tests.Info("Reflective: %#v", expected)
+		tests.Failed("Should have matching bytes between generated and reflective encoding")
+	}
+	tests.Passed("Should have matching bytes between generated and reflective encoding")
+}
+
+func TestGenerated_UnmarshalVoxa(t *testing.T) {
+	encoded, err := codecs.RecordCodec{}.NativeToBinary(reflective, nil)
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded value with record codec")
+	}
+	tests.Passed("Should have successfully encoded value with record codec")
+
+	var decoded fixtures.Person
+	if err := decoded.UnmarshalVoxa(encoded); err != nil {
+		tests.FailedWithError(err, "Should have successfully decoded value with generated method")
+	}
+	tests.Passed("Should have successfully decoded value with generated method")
+
+	if !reflect.DeepEqual(decoded, generated) {
+		tests.Info("Decoded: %#v", decoded)
+		tests.Failed("Should have matching values between input and decoded")
+	}
+	tests.Passed("Should have matching values between input and decoded")
+}
+
+func TestGenerated_RecordCodec(t *testing.T) {
+	encoded, err := generated.MarshalVoxa(0, nil)
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded value with generated method")
+	}
+	tests.Passed("Should have successfully encoded value with generated method")
+
+	var decoded person
+	if err := (codecs.RecordCodec{}).BinaryToNative(encoded, &decoded); err != nil {
+		tests.FailedWithError(err, "Should have successfully decoded value with record codec")
+	}
+	tests.Passed("Should have successfully decoded value with record codec")
+
+	if !reflect.DeepEqual(decoded, reflective) {
+		tests.Info("Decoded: %#v", decoded)
+		tests.Failed("Should have matching values between input and decoded")
+	}
+	tests.Passed("Should have matching values between input and decoded")
+}
+
+func TestGenerated_NilAndEmpty(t *testing.T) {
+	encoded, err := fixtures.Person{}.MarshalVoxa(0, nil)
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded value with generated method")
+	}
+	tests.Passed("Should have successfully encoded value with generated method")
+
+	expected, err := codecs.RecordCodec{}.NativeToBinary(person{}, nil)
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded value with record codec")
+	}
+	tests.Passed("Should have successfully encoded value with record codec")
+
+	if !bytes.Equal(encoded, expected) {
+		tests.Info("Generated: %#v", encoded)
+		tests.Info("Reflective: %#v", expected)
+		tests.Failed("Should have matching bytes between generated and reflective encoding")
+	}
+	tests.Passed("Should have matching bytes between generated and reflective encoding")
+
+	var decoded fixtures.Person
+	if err := decoded.UnmarshalVoxa(encoded); err != nil {
+		tests.FailedWithError(err, "Should have successfully decoded value with generated method")
+	}
+	tests.Passed("Should have successfully decoded value with generated method")
+
+	if decoded.Home != nil || decoded.OtherNames != nil || decoded.Stamp != nil {
+		tests.Failed("Should have left nil fields as nil")
+	}
+	tests.Passed("Should have left nil fields as nil")
+
+	if decoded.Nickname != "" || decoded.Retries != 0 || decoded.Timeout != 0 || decoded.Ratio != nil || decoded.Version != 0 {
+		tests.Info("Decoded: %#v", decoded)
+		tests.Failed("Should have kept zero values of fields with defaults")
+	}
+	tests.Passed("Should have kept zero values of fields with defaults")
+
+	var partial struct {
+		Name string `id:"2"`
+	}
+
+	encoded, err = codecs.RecordCodec{}.NativeToBinary(partial, nil)
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded value with record codec")
+	}
+
+	var missing fixtures.Person
+	if err := missing.UnmarshalVoxa(encoded); err != nil {
+		tests.FailedWithError(err, "Should have successfully decoded value with generated method")
+	}
+
+	if missing.Retries != 3 || missing.Timeout != 1500*time.Millisecond || missing.Ratio == nil || *missing.Ratio != 0.5 || missing.Entity.Version != 1 {
+		tests.Info("Decoded: %#v", missing)
+		tests.Failed("Should have set defaults of missing fields")
+	}
+	tests.Passed("Should have set defaults of missing fields")
+}
+
+func TestGenerated_EmptySlices(t *testing.T) {
+	empty, mirror := generated, reflective
+	empty.OtherNames, mirror.OtherNames = []string{}, []string{}
+	empty.Addresses, mirror.Addresses = []fixtures.Address{}, []address{}
+	empty.Matrix, mirror.Matrix = [][]int64{{}}, [][]int64{{}}
+
+	encoded, err := empty.MarshalVoxa(0, nil)
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded value with generated method")
+	}
+
+	var decoded fixtures.Person
+	if err := decoded.UnmarshalVoxa(encoded); err != nil {
+		tests.FailedWithError(err, "Should have successfully decoded value with generated method")
+	}
+
+	var expected person
+	if err := (codecs.RecordCodec{}).BinaryToNative(encoded, &expected); err != nil {
+		tests.FailedWithError(err, "Should have successfully decoded value with record codec")
+	}
+
+	if !reflect.DeepEqual(decoded, empty) || !reflect.DeepEqual(expected, mirror) {
+		tests.Info("Generated: %#v", decoded)
+		tests.Info("Reflective: %#v", expected)
+		tests.Failed("Should have decoded empty slices as empty rather than nil")
+	}
+	tests.Passed("Should have decoded empty slices as empty rather than nil")
+}
+
+func TestGenerated_Null(t *testing.T) {
+	// nil pointers are encoded as Null frames, which decode into the zero
+	// value of the fields.
+	encoded, err := voxa.Marshal(struct {
+		Age       *int        `id:"1"`
+		Name      *string     `id:"2"`
+		Addresses []*address  `id:"5"`
+		Date      *time.Time  `id:"7"`
+		Score     *float64    `id:"8"`
+		Active    *bool       `id:"9"`
+		Phase     *complex128 `id:"19"`
+		ID        *uint32     `id:"20"`
+	}{Addresses: []*address{nil, {Value: "Lagos"}}})
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded null fields")
+	}
+
+	decoded := generated
+	if err := decoded.UnmarshalVoxa(encoded); err != nil {
+		tests.FailedWithError(err, "Should have successfully decoded null fields with generated method")
+	}
+
+	expected := reflective
+	if err := (codecs.RecordCodec{}).BinaryToNative(encoded, &expected); err != nil {
+		tests.FailedWithError(err, "Should have successfully decoded null fields with record codec")
+	}
+
+	zero := []interface{}{0, "", time.Time{}, 0.0, false, complex128(0), uint32(0)}
+	generatedFields := []interface{}{decoded.Age, decoded.Name, decoded.Date, decoded.Score, decoded.Active, decoded.Phase, decoded.ID}
+	reflectiveFields := []interface{}{expected.Age, expected.Name, expected.Date, expected.Score, expected.Active, expected.Phase, expected.ID}
+
+	if !reflect.DeepEqual(generatedFields, zero) || !reflect.DeepEqual(reflectiveFields, zero) ||
+		!reflect.DeepEqual(decoded.Addresses, []fixtures.Address{{}, {Value: "Lagos"}}) ||
+		!reflect.DeepEqual(expected.Addresses, []address{{}, {Value: "Lagos"}}) {
+		tests.Info("Generated: %#v", decoded)
+		tests.Info("Reflective: %#v", expected)
+		tests.Failed("Should have decoded null fields into their zero value")
+	}
+	tests.Passed("Should have decoded null fields into their zero value")
+}
+
+func TestGenerated_ZeroDefaults(t *testing.T) {
+	var zero float32
+	value, mirror := generated, reflective
+	value.Retries, mirror.Retries = 0, 0
+	value.Ratio, mirror.Ratio = &zero, &zero
+
+	encoded, err := value.MarshalVoxa(0, nil)
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded value with generated method")
+	}
+
+	var decoded fixtures.Person
+	if err := decoded.UnmarshalVoxa(encoded); err != nil {
+		tests.FailedWithError(err, "Should have successfully decoded value with generated method")
+	}
+
+	var expected person
+	if err := (codecs.RecordCodec{}).BinaryToNative(encoded, &expected); err != nil {
+		tests.FailedWithError(err, "Should have successfully decoded value with record codec")
+	}
+
+	// zero values of fields with a default are encoded, hence kept.
+	if decoded.Retries != 0 || expected.Retries != 0 || *decoded.Ratio != 0 || *expected.Ratio != 0 {
+		tests.Info("Generated: %#v", decoded)
+		tests.Info("Reflective: %#v", expected)
+		tests.Failed("Should have kept zero values of fields with defaults with generated and reflective codecs")
+	}
+	tests.Passed("Should have kept zero values of fields with defaults with generated and reflective codecs")
+}
+
+func TestGenerated_Required(t *testing.T) {
+	var partial struct {
+		Address string `id:"3"`
+	}
+
+	encoded, err := codecs.RecordCodec{}.NativeToBinary(partial, nil)
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded value with record codec")
+	}
+	tests.Passed("Should have successfully encoded value with record codec")
+
+	var decoded fixtures.Person
+	err = decoded.UnmarshalVoxa(encoded)
+
+	fieldErr, ok := err.(*voxa.FieldError)
+	if !ok || !reflect.DeepEqual(fieldErr.Missing, []voxa.FieldID{2}) {
+		tests.Info("Received: %#v", err)
+		tests.Failed("Should have failed with missing required field ids")
+	}
+	tests.Passed("Should have failed with missing required field ids")
+}
+
+func TestGenerated_Overflow(t *testing.T) {
+	for _, item := range []interface{}{
+		struct {
+			Name string `id:"2"`
+			ID   int64  `id:"20"`
+		}{Name: "Alex Woodpecker", ID: 1 << 40},
+		struct {
+			Name string  `id:"2"`
+			Age  float64 `id:"1"`
+		}{Name: "Alex Woodpecker", Age: 3.9},
+	} {
+		encoded, err := codecs.RecordCodec{}.NativeToBinary(item, nil)
+		if err != nil {
+			tests.FailedWithError(err, "Should have successfully encoded value with record codec")
+		}
+
+		var decoded fixtures.Person
+		if err := decoded.UnmarshalVoxa(encoded); err == nil {
+			tests.Info("Decoded: %#v", decoded)
+			tests.Failed("Should have failed to decode %#v with generated method", item)
+		}
+
+		var expected person
+		if err := (codecs.RecordCodec{}).BinaryToNative(encoded, &expected); err == nil {
+			tests.Info("Decoded: %#v", expected)
+			tests.Failed("Should have failed to decode %#v with record codec", item)
+		}
+	}
+	tests.Passed("Should have failed to decode values which do not fit into fields")
+}
+
+func TestGenerated_Limits(t *testing.T) {
+	encoded, err := codecs.RecordCodec{}.NativeToBinary(reflective, nil)
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded value with record codec")
+	}
+
+	// addresses are records within a list within the record of a person.
+	limits := voxa.Limits{MaxDepth: 2}
+
+	var decoded fixtures.Person
+	if err := voxa.UnmarshalWithLimits(encoded, &decoded, limits); !errors.Is(err, voxa.ErrLimitExceeded) {
+		tests.Info("Received: %+q", err)
+		tests.Failed("Should have enforced depth limit within generated type")
+	}
+
+	var expected person
+	if err := voxa.UnmarshalWithLimits(encoded, &expected, limits); !errors.Is(err, voxa.ErrLimitExceeded) {
+		tests.Info("Received: %+q", err)
+		tests.Failed("Should have enforced depth limit within reflective type")
+	}
+	tests.Passed("Should have enforced depth limit within generated and reflective types")
+
+	limits.MaxDepth = 3
+	if err := voxa.UnmarshalWithLimits(encoded, &decoded, limits); err != nil {
+		tests.FailedWithError(err, "Should have successfully decoded value within depth limit")
+	}
+	if err := voxa.UnmarshalWithLimits(encoded, &expected, limits); err != nil {
+		tests.FailedWithError(err, "Should have successfully decoded value within depth limit")
+	}
+	tests.Passed("Should have successfully decoded value within depth limit")
+
+	limits = voxa.Limits{MaxBlockCount: 1}
+	if err := voxa.UnmarshalWithLimits(encoded, &decoded, limits); !errors.Is(err, voxa.ErrLimitExceeded) {
+		tests.Info("Received: %+q", err)
+		tests.Failed("Should have enforced block count limit within generated type")
+	}
+	if err := voxa.UnmarshalWithLimits(encoded, &expected, limits); !errors.Is(err, voxa.ErrLimitExceeded) {
+		tests.Info("Received: %+q", err)
+		tests.Failed("Should have enforced block count limit within reflective type")
+	}
+	tests.Passed("Should have enforced block count limit within generated and reflective types")
+
+	newer := struct {
+		Name string `id:"2"`
+		Home struct {
+			Value string `id:"1"`
+			Color string `id:"30"`
+		} `id:"6"`
+	}{Name: "Alex Woodpecker"}
+
+	encoded, err = codecs.RecordCodec{}.NativeToBinary(newer, nil)
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded value with record codec")
+	}
+
+	limits = voxa.Limits{DisallowUnknownFields: true}
+
+	var fieldErr *voxa.FieldError
+	if err := voxa.UnmarshalWithLimits(encoded, &decoded, limits); !errors.As(err, &fieldErr) || !reflect.DeepEqual(fieldErr.Unknown, []voxa.FieldID{30}) {
+		tests.Info("Received: %+q", err)
+		tests.Failed("Should have failed on unknown fields within generated type")
+	}
+	if err := voxa.UnmarshalWithLimits(encoded, &expected, limits); !errors.As(err, &fieldErr) || !reflect.DeepEqual(fieldErr.Unknown, []voxa.FieldID{30}) {
+		tests.Info("Received: %+q", err)
+		tests.Failed("Should have failed on unknown fields within reflective type")
+	}
+	tests.Passed("Should have failed on unknown fields within generated and reflective types")
+}
+
+func TestGenerated_Unknown(t *testing.T) {
+	newer := struct {
+		Name  string   `id:"2"`
+		Color string   `id:"30"`
+		Tags  []string `id:"31"`
+	}{Name: "Alex Woodpecker", Color: "blue", Tags: []string{"new"}}
+
+	encoded, err := codecs.RecordCodec{}.NativeToBinary(newer, nil)
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded value with record codec")
+	}
+	tests.Passed("Should have successfully encoded value with record codec")
+
+	var decoded fixtures.Person
+	if err := decoded.UnmarshalVoxa(encoded); err != nil {
+		tests.FailedWithError(err, "Should have successfully decoded value with generated method")
+	}
+	tests.Passed("Should have successfully decoded value with generated method")
+
+	var expected person
+	if err := (codecs.RecordCodec{}).BinaryToNative(encoded, &expected); err != nil {
+		tests.FailedWithError(err, "Should have successfully decoded value with record codec")
+	}
+	tests.Passed("Should have successfully decoded value with record codec")
+
+	if len(decoded.Extra) == 0 || !bytes.Equal(decoded.Extra, expected.Extra) {
+		tests.Info("Generated: %#v", decoded.Extra)
+		tests.Info("Reflective: %#v", expected.Extra)
+		tests.Failed("Should have kept matching unknown fields")
+	}
+	tests.Passed("Should have kept matching unknown fields")
+
+	relayed, err := decoded.MarshalVoxa(0, nil)
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded value with generated method")
+	}
+
+	reflected, err := codecs.RecordCodec{}.NativeToBinary(expected, nil)
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded value with record codec")
+	}
+
+	if !bytes.Equal(relayed, reflected) {
+		tests.Info("Generated: %#v", relayed)
+		tests.Info("Reflective: %#v", reflected)
+		tests.Failed("Should have matching bytes between generated and reflective encoding")
+	}
+	tests.Passed("Should have matching bytes between generated and reflective encoding")
+}
+
+func TestGenerated_Union(t *testing.T) {
+	voxa.RegisterType(1, fixtures.Address{})
+
+	union, mirror := generated, reflective
+	union.Note = fixtures.Address{Value: "Accra"}
+	mirror.Note = fixtures.Address{Value: "Accra"}
+
+	encoded, err := union.MarshalVoxa(0, nil)
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded value with generated method")
+	}
+	tests.Passed("Should have successfully encoded value with generated method")
+
+	expected, err := codecs.RecordCodec{}.NativeToBinary(mirror, nil)
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded value with record codec")
+	}
+	tests.Passed("Should have successfully encoded value with record codec")
+
+	if !bytes.Equal(encoded, expected) {
+		tests.Info("Generated: %#v", encoded)
+		tests.Info("Reflective: %#v", expected)
+		tests.Failed("Should have matching bytes between generated and reflective encoding")
+	}
+	tests.Passed("Should have matching bytes between generated and reflective encoding")
+
+	var decoded fixtures.Person
+	if err := decoded.UnmarshalVoxa(encoded); err != nil {
+		tests.FailedWithError(err, "Should have successfully decoded value with generated method")
+	}
+	tests.Passed("Should have successfully decoded value with generated method")
+
+	if decoded.Note != union.Note {
+		tests.Info("Decoded: %#v", decoded.Note)
+		tests.Failed("Should have decoded interface value into it's registered type")
+	}
+	tests.Passed("Should have decoded interface value into it's registered type")
+}
@@ -0,0 +1,788 @@
+// Code generated by voxagen. DO NOT EDIT.
+
+package fixtures
+
+import (
+	"github.com/wirekit/voxa"
+	"github.com/wirekit/voxa/codecs"
+	"time"
+)
+
+// MarshalVoxa implements the voxa.Marshaler interface.
+func (v Address) MarshalVoxa(id voxa.FieldID, c []byte) ([]byte, error) {
+	var err error
+	c, start := codecs.ReserveFrame(c)
+	c = codecs.AppendHeader(c, voxa.Record, id)
+	if c, err = codecs.AppendFrame(codecs.TextCodec{}, v.Value, 1, c); err != nil {
+		return nil, err
+	}
+	return codecs.CloseFrame(c, start), nil
+}
+
+// UnmarshalVoxa implements the voxa.Unmarshaler interface.
+func (v *Address) UnmarshalVoxa(b []byte) error {
+	return v.UnmarshalVoxaLimits(b, voxa.DefaultLimits())
+}
+
+// UnmarshalVoxaLimits implements the voxa.LimitedUnmarshaler interface.
+func (v *Address) UnmarshalVoxaLimits(b []byte, limits voxa.Limits) error {
+	limits, err := codecs.EnterRecord(limits)
+	if err != nil {
+		return err
+	}
+
+	_, content, _, err := codecs.NextFrame(b)
+	if err != nil {
+		return err
+	}
+
+	atom, _, fields, err := codecs.ReadHeader(content)
+	if err != nil {
+		return err
+	}
+
+	if atom != voxa.Record {
+		return codecs.ErrNotRecord
+	}
+
+	var unknown []voxa.FieldID
+	for len(fields) > 0 {
+		_, content, rest, err := codecs.NextFrame(fields)
+		if err != nil {
+			return err
+		}
+
+		_, id, _, err := codecs.ReadHeader(content)
+		if err != nil {
+			return err
+		}
+
+		switch id {
+		case 1:
+			if codecs.IsNull(content) {
+				v.Value = ""
+			} else {
+				value, err := codecs.FrameToText(content)
+				if err != nil {
+					return err
+				}
+				v.Value = value
+			}
+		default:
+			if limits.DisallowUnknownFields {
+				unknown = append(unknown, id)
+			}
+		}
+
+		fields = rest
+	}
+
+	if unknown != nil {
+		return &voxa.FieldError{Unknown: unknown}
+	}
+
+	return nil
+}
+
+// MarshalVoxa implements the voxa.Marshaler interface.
+func (v Entity) MarshalVoxa(id voxa.FieldID, c []byte) ([]byte, error) {
+	var err error
+	c, start := codecs.ReserveFrame(c)
+	c = codecs.AppendHeader(c, voxa.Record, id)
+	if c, err = codecs.AppendFrame(codecs.IntCodec{}, v.ID, 20, c); err != nil {
+		return nil, err
+	}
+	if c, err = codecs.AppendFrame(codecs.IntCodec{}, v.Version, 21, c); err != nil {
+		return nil, err
+	}
+	return codecs.CloseFrame(c, start), nil
+}
+
+// UnmarshalVoxa implements the voxa.Unmarshaler interface.
+func (v *Entity) UnmarshalVoxa(b []byte) error {
+	return v.UnmarshalVoxaLimits(b, voxa.DefaultLimits())
+}
+
+// UnmarshalVoxaLimits implements the voxa.LimitedUnmarshaler interface.
+func (v *Entity) UnmarshalVoxaLimits(b []byte, limits voxa.Limits) error {
+	limits, err := codecs.EnterRecord(limits)
+	if err != nil {
+		return err
+	}
+
+	_, content, _, err := codecs.NextFrame(b)
+	if err != nil {
+		return err
+	}
+
+	atom, _, fields, err := codecs.ReadHeader(content)
+	if err != nil {
+		return err
+	}
+
+	if atom != voxa.Record {
+		return codecs.ErrNotRecord
+	}
+
+	// defaults are set first, hence only fields missing from the record
+	// keep them.
+	v.Version = 1
+
+	var unknown []voxa.FieldID
+	for len(fields) > 0 {
+		_, content, rest, err := codecs.NextFrame(fields)
+		if err != nil {
+			return err
+		}
+
+		_, id, _, err := codecs.ReadHeader(content)
+		if err != nil {
+			return err
+		}
+
+		switch id {
+		case 20:
+			if codecs.IsNull(content) {
+				v.ID = 0
+			} else {
+				value, err := codecs.FrameToUint(content, 32)
+				if err != nil {
+					return err
+				}
+				v.ID = uint32(value)
+			}
+		case 21:
+			if codecs.IsNull(content) {
+				v.Version = 0
+			} else {
+				value, err := codecs.FrameToInt(content, 0)
+				if err != nil {
+					return err
+				}
+				v.Version = int(value)
+			}
+		default:
+			if limits.DisallowUnknownFields {
+				unknown = append(unknown, id)
+			}
+		}
+
+		fields = rest
+	}
+
+	if unknown != nil {
+		return &voxa.FieldError{Unknown: unknown}
+	}
+
+	return nil
+}
+
+// MarshalVoxa implements the voxa.Marshaler interface.
+func (v Person) MarshalVoxa(id voxa.FieldID, c []byte) ([]byte, error) {
+	var err error
+	c, start := codecs.ReserveFrame(c)
+	c = codecs.AppendHeader(c, voxa.Record, id)
+	if c, err = codecs.AppendFrame(codecs.IntCodec{}, v.Age, 1, c); err != nil {
+		return nil, err
+	}
+	if c, err = codecs.AppendFrame(codecs.TextCodec{}, v.Name, 2, c); err != nil {
+		return nil, err
+	}
+	if c, err = codecs.AppendFrame(codecs.TextCodec{}, v.Address, 3, c); err != nil {
+		return nil, err
+	}
+	if v.OtherNames == nil {
+		c = codecs.AppendNull(c, 4)
+	} else {
+		var mark0 int
+		c, mark0 = codecs.ReserveFrame(c)
+		c = codecs.AppendHeader(c, voxa.List, 4)
+		for i0, item0 := range v.OtherNames {
+			if c, err = codecs.AppendFrame(codecs.TextCodec{}, item0, voxa.FieldID(i0), c); err != nil {
+				return nil, err
+			}
+		}
+		c = codecs.CloseFrame(c, mark0)
+	}
+	if v.Addresses == nil {
+		c = codecs.AppendNull(c, 5)
+	} else {
+		var mark0 int
+		c, mark0 = codecs.ReserveFrame(c)
+		c = codecs.AppendHeader(c, voxa.List, 5)
+		for i0, item0 := range v.Addresses {
+			if c, err = item0.MarshalVoxa(voxa.FieldID(i0), c); err != nil {
+				return nil, err
+			}
+		}
+		c = codecs.CloseFrame(c, mark0)
+	}
+	if v.Home != nil {
+		if c, err = v.Home.MarshalVoxa(6, c); err != nil {
+			return nil, err
+		}
+	} else {
+		c = codecs.AppendNull(c, 6)
+	}
+	if c, err = codecs.AppendFrame(codecs.TimeCodec{}, v.Date, 7, c); err != nil {
+		return nil, err
+	}
+	if c, err = codecs.AppendFrame(codecs.FloatCodec{}, v.Score, 8, c); err != nil {
+		return nil, err
+	}
+	if c, err = codecs.AppendFrame(codecs.BooleanCodec{}, v.Active, 9, c); err != nil {
+		return nil, err
+	}
+	if v.Matrix == nil {
+		c = codecs.AppendNull(c, 10)
+	} else {
+		var mark0 int
+		c, mark0 = codecs.ReserveFrame(c)
+		c = codecs.AppendHeader(c, voxa.List, 10)
+		for i0, item0 := range v.Matrix {
+			if item0 == nil {
+				c = codecs.AppendNull(c, voxa.FieldID(i0))
+			} else {
+				var mark1 int
+				c, mark1 = codecs.ReservePacked(c, voxa.SInt64, voxa.FieldID(i0), len(item0))
+				for _, item1 := range item0 {
+					c = codecs.AppendPackedInt(c, voxa.SInt64, item1)
+				}
+				c = codecs.CloseFrame(c, mark1)
+			}
+		}
+		c = codecs.CloseFrame(c, mark0)
+	}
+	if v.Counts == nil {
+		c = codecs.AppendNull(c, 11)
+	} else {
+		var mark0 int
+		c, mark0 = codecs.ReservePacked(c, voxa.UInt16, 11, len(v.Counts))
+		for _, item0 := range v.Counts {
+			c = codecs.AppendPackedUint(c, voxa.UInt16, uint64(item0))
+		}
+		c = codecs.CloseFrame(c, mark0)
+	}
+	if c, err = codecs.AppendValue(&v.Note, 12, c); err != nil {
+		return nil, err
+	}
+	if v.Nickname != "" {
+		if c, err = codecs.AppendFrame(codecs.TextCodec{}, v.Nickname, 13, c); err != nil {
+			return nil, err
+		}
+	}
+	if c, err = codecs.AppendFrame(codecs.IntCodec{}, v.Retries, 14, c); err != nil {
+		return nil, err
+	}
+	if c, err = codecs.AppendFrame(codecs.IntCodec{}, int64(v.Timeout), 15, c); err != nil {
+		return nil, err
+	}
+	if v.Ratio != nil {
+		if c, err = codecs.AppendFrame(codecs.FloatCodec{}, (*v.Ratio), 16, c); err != nil {
+			return nil, err
+		}
+	} else {
+		c = codecs.AppendNull(c, 16)
+	}
+	if c, err = codecs.AppendValue(v.Digest, 17, c); err != nil {
+		return nil, err
+	}
+	if c, err = codecs.AppendValue(v.Position, 18, c); err != nil {
+		return nil, err
+	}
+	if c, err = codecs.AppendFrame(codecs.ComplexCodec{}, v.Phase, 19, c); err != nil {
+		return nil, err
+	}
+	if v.Samples == nil {
+		c = codecs.AppendNull(c, 23)
+	} else {
+		var mark0 int
+		c, mark0 = codecs.ReservePacked(c, voxa.Complex64, 23, len(v.Samples))
+		for _, item0 := range v.Samples {
+			c = codecs.AppendPackedComplex(c, voxa.Complex64, complex128(item0))
+		}
+		c = codecs.CloseFrame(c, mark0)
+	}
+	if v.Expires != nil {
+		if c, err = codecs.AppendFrame(codecs.TimeCodec{}, (*v.Expires), 24, c); err != nil {
+			return nil, err
+		}
+	} else {
+		c = codecs.AppendNull(c, 24)
+	}
+	if v.Raw == nil {
+		c = codecs.AppendNull(c, 25)
+	} else {
+		if c, err = codecs.AppendFrame(codecs.BytesCodec{}, v.Raw, 25, c); err != nil {
+			return nil, err
+		}
+	}
+	if c, err = codecs.AppendFrame(codecs.IntCodec{}, v.Entity.ID, 20, c); err != nil {
+		return nil, err
+	}
+	if c, err = codecs.AppendFrame(codecs.IntCodec{}, v.Entity.Version, 21, c); err != nil {
+		return nil, err
+	}
+	if v.Stamp != nil {
+		if c, err = codecs.AppendFrame(codecs.TextCodec{}, v.Stamp.Author, 22, c); err != nil {
+			return nil, err
+		}
+	}
+	c = append(c, v.Extra...)
+	return codecs.CloseFrame(c, start), nil
+}
+
+// UnmarshalVoxa implements the voxa.Unmarshaler interface.
+func (v *Person) UnmarshalVoxa(b []byte) error {
+	return v.UnmarshalVoxaLimits(b, voxa.DefaultLimits())
+}
+
+// UnmarshalVoxaLimits implements the voxa.LimitedUnmarshaler interface.
+func (v *Person) UnmarshalVoxaLimits(b []byte, limits voxa.Limits) error {
+	limits, err := codecs.EnterRecord(limits)
+	if err != nil {
+		return err
+	}
+
+	_, content, _, err := codecs.NextFrame(b)
+	if err != nil {
+		return err
+	}
+
+	atom, _, fields, err := codecs.ReadHeader(content)
+	if err != nil {
+		return err
+	}
+
+	if atom != voxa.Record {
+		return codecs.ErrNotRecord
+	}
+
+	// defaults are set first, hence only fields missing from the record
+	// keep them.
+	v.Retries = 3
+	if err := codecs.SetDefault(&v.Timeout, "1.5s"); err != nil {
+		return err
+	}
+	if err := codecs.SetDefault(&v.Ratio, "0.5"); err != nil {
+		return err
+	}
+	v.Entity.Version = 1
+
+	var seen2 bool
+
+	// unknown frames are only those of the decoded record.
+	v.Extra = nil
+
+	for len(fields) > 0 {
+		frame, content, rest, err := codecs.NextFrame(fields)
+		if err != nil {
+			return err
+		}
+
+		_, id, _, err := codecs.ReadHeader(content)
+		if err != nil {
+			return err
+		}
+
+		switch id {
+		case 1:
+			if codecs.IsNull(content) {
+				v.Age = 0
+			} else {
+				value, err := codecs.FrameToInt(content, 0)
+				if err != nil {
+					return err
+				}
+				v.Age = int(value)
+			}
+		case 2:
+			if codecs.IsNull(content) {
+				v.Name = ""
+			} else {
+				value, err := codecs.FrameToText(content)
+				if err != nil {
+					return err
+				}
+				v.Name = value
+			}
+			seen2 = true
+		case 3:
+			if codecs.IsNull(content) {
+				v.Address = ""
+			} else {
+				value, err := codecs.FrameToText(content)
+				if err != nil {
+					return err
+				}
+				v.Address = value
+			}
+		case 4:
+			atom, _, items0, err := codecs.ReadHeader(content)
+			if err != nil {
+				return err
+			}
+
+			if atom != voxa.List && atom != voxa.Null {
+				return codecs.ErrNotList
+			}
+
+			var list0 []string
+			if atom == voxa.List {
+				_, count0, err := codecs.EnterList(limits, items0)
+				if err != nil {
+					return err
+				}
+
+				list0 = make([]string, 0, count0)
+				for len(items0) > 0 {
+					_, content0, rest0, err := codecs.NextFrame(items0)
+					if err != nil {
+						return err
+					}
+
+					var item0 string
+					if codecs.IsNull(content0) {
+						item0 = ""
+					} else {
+						value, err := codecs.FrameToText(content0)
+						if err != nil {
+							return err
+						}
+						item0 = value
+					}
+					list0 = append(list0, item0)
+					items0 = rest0
+				}
+			}
+
+			v.OtherNames = list0
+		case 5:
+			atom, _, items0, err := codecs.ReadHeader(content)
+			if err != nil {
+				return err
+			}
+
+			if atom != voxa.List && atom != voxa.Null {
+				return codecs.ErrNotList
+			}
+
+			var list0 []Address
+			if atom == voxa.List {
+				limits0, count0, err := codecs.EnterList(limits, items0)
+				if err != nil {
+					return err
+				}
+
+				list0 = make([]Address, 0, count0)
+				for len(items0) > 0 {
+					frame0, content0, rest0, err := codecs.NextFrame(items0)
+					if err != nil {
+						return err
+					}
+
+					var item0 Address
+					if codecs.IsNull(content0) {
+						item0 = Address{}
+					} else {
+						if err := item0.UnmarshalVoxaLimits(frame0, limits0); err != nil {
+							return err
+						}
+					}
+					list0 = append(list0, item0)
+					items0 = rest0
+				}
+			}
+
+			v.Addresses = list0
+		case 6:
+			if codecs.IsNull(content) {
+				v.Home = nil
+			} else {
+				if v.Home == nil {
+					v.Home = new(Address)
+				}
+				if err := v.Home.UnmarshalVoxaLimits(frame, limits); err != nil {
+					return err
+				}
+			}
+		case 7:
+			if codecs.IsNull(content) {
+				v.Date = time.Time{}
+			} else {
+				value, err := codecs.FrameToTime(content)
+				if err != nil {
+					return err
+				}
+				v.Date = value
+			}
+		case 8:
+			if codecs.IsNull(content) {
+				v.Score = 0
+			} else {
+				value, err := codecs.FrameToFloat(content, 64)
+				if err != nil {
+					return err
+				}
+				v.Score = value
+			}
+		case 9:
+			if codecs.IsNull(content) {
+				v.Active = false
+			} else {
+				value, err := codecs.FrameToBool(content)
+				if err != nil {
+					return err
+				}
+				v.Active = value
+			}
+		case 10:
+			atom, _, items0, err := codecs.ReadHeader(content)
+			if err != nil {
+				return err
+			}
+
+			if atom != voxa.List && atom != voxa.Null {
+				return codecs.ErrNotList
+			}
+
+			var list0 [][]int64
+			if atom == voxa.List {
+				limits0, count0, err := codecs.EnterList(limits, items0)
+				if err != nil {
+					return err
+				}
+
+				list0 = make([][]int64, 0, count0)
+				for len(items0) > 0 {
+					frame0, _, rest0, err := codecs.NextFrame(items0)
+					if err != nil {
+						return err
+					}
+
+					var item0 []int64
+					if err := codecs.DecodeValueLimits(frame0, &item0, limits0); err != nil {
+						return err
+					}
+					list0 = append(list0, item0)
+					items0 = rest0
+				}
+			}
+
+			v.Matrix = list0
+		case 11:
+			if err := codecs.DecodeValueLimits(frame, &v.Counts, limits); err != nil {
+				return err
+			}
+		case 12:
+			if err := codecs.DecodeValueLimits(frame, &v.Note, limits); err != nil {
+				return err
+			}
+		case 13:
+			if codecs.IsNull(content) {
+				v.Nickname = ""
+			} else {
+				value, err := codecs.FrameToText(content)
+				if err != nil {
+					return err
+				}
+				v.Nickname = value
+			}
+		case 14:
+			if codecs.IsNull(content) {
+				v.Retries = 0
+			} else {
+				value, err := codecs.FrameToInt(content, 0)
+				if err != nil {
+					return err
+				}
+				v.Retries = int(value)
+			}
+		case 15:
+			if codecs.IsNull(content) {
+				v.Timeout = 0
+			} else {
+				value, err := codecs.FrameToInt(content, 64)
+				if err != nil {
+					return err
+				}
+				v.Timeout = time.Duration(value)
+			}
+		case 16:
+			if codecs.IsNull(content) {
+				v.Ratio = nil
+			} else {
+				if v.Ratio == nil {
+					v.Ratio = new(float32)
+				}
+				value, err := codecs.FrameToFloat(content, 32)
+				if err != nil {
+					return err
+				}
+				(*v.Ratio) = float32(value)
+			}
+		case 17:
+			if err := codecs.DecodeValueLimits(frame, &v.Digest, limits); err != nil {
+				return err
+			}
+		case 18:
+			if err := codecs.DecodeValueLimits(frame, &v.Position, limits); err != nil {
+				return err
+			}
+		case 19:
+			if codecs.IsNull(content) {
+				v.Phase = 0
+			} else {
+				value, err := codecs.FrameToComplex128(content)
+				if err != nil {
+					return err
+				}
+				v.Phase = value
+			}
+		case 23:
+			if err := codecs.DecodeValueLimits(frame, &v.Samples, limits); err != nil {
+				return err
+			}
+		case 24:
+			if codecs.IsNull(content) {
+				v.Expires = nil
+			} else {
+				if v.Expires == nil {
+					v.Expires = new(time.Time)
+				}
+				value, err := codecs.FrameToTime(content)
+				if err != nil {
+					return err
+				}
+				(*v.Expires) = value
+			}
+		case 25:
+			if err := codecs.DecodeValueLimits(frame, &v.Raw, limits); err != nil {
+				return err
+			}
+		case 20:
+			if codecs.IsNull(content) {
+				v.Entity.ID = 0
+			} else {
+				value, err := codecs.FrameToUint(content, 32)
+				if err != nil {
+					return err
+				}
+				v.Entity.ID = uint32(value)
+			}
+		case 21:
+			if codecs.IsNull(content) {
+				v.Entity.Version = 0
+			} else {
+				value, err := codecs.FrameToInt(content, 0)
+				if err != nil {
+					return err
+				}
+				v.Entity.Version = int(value)
+			}
+		case 22:
+			if v.Stamp == nil {
+				v.Stamp = new(Stamp)
+			}
+			if codecs.IsNull(content) {
+				v.Stamp.Author = ""
+			} else {
+				value, err := codecs.FrameToText(content)
+				if err != nil {
+					return err
+				}
+				v.Stamp.Author = value
+			}
+		default:
+			v.Extra = append(v.Extra, frame...)
+		}
+
+		fields = rest
+	}
+
+	var missing []voxa.FieldID
+	if !seen2 {
+		missing = append(missing, 2)
+	}
+	if missing != nil {
+		return &voxa.FieldError{Missing: missing}
+	}
+
+	return nil
+}
+
+// MarshalVoxa implements the voxa.Marshaler interface.
+func (v Stamp) MarshalVoxa(id voxa.FieldID, c []byte) ([]byte, error) {
+	var err error
+	c, start := codecs.ReserveFrame(c)
+	c = codecs.AppendHeader(c, voxa.Record, id)
+	if c, err = codecs.AppendFrame(codecs.TextCodec{}, v.Author, 22, c); err != nil {
+		return nil, err
+	}
+	return codecs.CloseFrame(c, start), nil
+}
+
+// UnmarshalVoxa implements the voxa.Unmarshaler interface.
+func (v *Stamp) UnmarshalVoxa(b []byte) error {
+	return v.UnmarshalVoxaLimits(b, voxa.DefaultLimits())
+}
+
+// UnmarshalVoxaLimits implements the voxa.LimitedUnmarshaler interface.
+func (v *Stamp) UnmarshalVoxaLimits(b []byte, limits voxa.Limits) error {
+	limits, err := codecs.EnterRecord(limits)
+	if err != nil {
+		return err
+	}
+
+	_, content, _, err := codecs.NextFrame(b)
+	if err != nil {
+		return err
+	}
+
+	atom, _, fields, err := codecs.ReadHeader(content)
+	if err != nil {
+		return err
+	}
+
+	if atom != voxa.Record {
+		return codecs.ErrNotRecord
+	}
+
+	var unknown []voxa.FieldID
+	for len(fields) > 0 {
+		_, content, rest, err := codecs.NextFrame(fields)
+		if err != nil {
+			return err
+		}
+
+		_, id, _, err := codecs.ReadHeader(content)
+		if err != nil {
+			return err
+		}
+
+		switch id {
+		case 22:
+			if codecs.IsNull(content) {
+				v.Author = ""
+			} else {
+				value, err := codecs.FrameToText(content)
+				if err != nil {
+					return err
+				}
+				v.Author = value
+			}
+		default:
+			if limits.DisallowUnknownFields {
+				unknown = append(unknown, id)
+			}
+		}
+
+		fields = rest
+	}
+
+	if unknown != nil {
+		return &voxa.FieldError{Unknown: unknown}
+	}
+
+	return nil
+}
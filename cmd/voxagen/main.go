@@ -0,0 +1,72 @@
+// Command voxagen generates reflection free MarshalVoxa and UnmarshalVoxa
+// methods for structs with `id` tags. The generated methods write the exact
+// byte layout of the codecs.RecordCodec, hence values encoded by either can
+// be decoded by the other.
+//
+// Usage:
+//
+//	voxagen [-type T,U] [-output file] [directory]
+//
+// By default all structs of the package in the directory which have at least
+// one `id` tag are generated into `<package>_voxa.go`. It is typically used
+// from a go:generate directive:
+//
+//	//go:generate voxagen -type Person,Address
+//
+// Fields whose types can not be encoded without reflection, such as maps,
+// interfaces and structs from other packages without voxa methods, are
+// encoded and decoded with the reflective codecs.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("voxagen: ")
+
+	typeNames := flag.String("type", "", "comma-separated list of struct type names; defaults to all structs with id tags")
+	output := flag.String("output", "", "output file name; defaults to <package>_voxa.go in the package directory")
+
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: voxagen [-type T,U] [-output file] [directory]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+
+	var names []string
+	if *typeNames != "" {
+		names = strings.Split(*typeNames, ",")
+	}
+
+	pkg, err := loadPackage(dir, *output)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	src, err := generate(pkg, names)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	target := *output
+	if target == "" {
+		target = filepath.Join(dir, pkg.Name()+"_voxa.go")
+	}
+
+	if err := ioutil.WriteFile(target, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/influx6/faux/tests"
+)
+
+var fixturesDir = filepath.Join("internal", "fixtures")
+
+func TestGenerate_Fixtures(t *testing.T) {
+	pkg, err := loadPackage(fixturesDir, "")
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully loaded fixtures package")
+	}
+	tests.Passed("Should have successfully loaded fixtures package")
+
+	src, err := generate(pkg, nil)
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully generated fixtures methods")
+	}
+	tests.Passed("Should have successfully generated fixtures methods")
+
+	committed, err := ioutil.ReadFile(filepath.Join(fixturesDir, "fixtures_voxa.go"))
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully read generated fixtures file")
+	}
+	tests.Passed("Should have successfully read generated fixtures file")
+
+	if !bytes.Equal(src, committed) {
+		tests.Failed("Should have matching generated code, run go generate within fixtures package")
+	}
+	tests.Passed("Should have matching generated code")
+}
+
+func TestGenerate_UnknownType(t *testing.T) {
+	pkg, err := loadPackage(fixturesDir, "")
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully loaded fixtures package")
+	}
+	tests.Passed("Should have successfully loaded fixtures package")
+
+	if _, err := generate(pkg, []string{"Unknown"}); err == nil {
+		tests.Failed("Should have failed to generate methods for unknown type")
+	}
+	tests.Passed("Should have failed to generate methods for unknown type")
+}
+
+func TestGenerate_InvalidDefaults(t *testing.T) {
+	sources := map[string]string{
+		"int default overflowing 32 bits": "Count int `id:\"1\" default:\"3000000000\"`",
+		"both omitempty and a default":    "Count int `id:\"1,omitempty\" default:\"3\"`",
+	}
+
+	if _, err := generateSource("package big\n\ntype Big struct {\n\tCount int `id:\"1\" default:\"3\"`\n}\n"); err != nil {
+		tests.FailedWithError(err, "Should have successfully generated methods for field with a default")
+	}
+	tests.Passed("Should have successfully generated methods for field with a default")
+
+	for name, field := range sources {
+		if _, err := generateSource("package big\n\ntype Big struct {\n\t" + field + "\n}\n"); err == nil {
+			tests.Failed("Should have failed to generate methods for field with %s", name)
+		}
+		tests.Passed("Should have failed to generate methods for field with %s", name)
+	}
+}
+
+// generateSource generates the methods of type Big declared by provided
+// package source.
+func generateSource(src string) ([]byte, error) {
+	dir, err := ioutil.TempDir("", "voxagen")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "big.go"), []byte(src), 0644); err != nil {
+		return nil, err
+	}
+
+	pkg, err := loadPackage(dir, "")
+	if err != nil {
+		return nil, err
+	}
+	return generate(pkg, []string{"Big"})
+}
@@ -161,3 +161,115 @@ func BenchmarkRecordCodec_JSONNativeToBinary(b *testing.B) {
 	}
 	b.StopTimer()
 }
+
+func BenchmarkRecordCodec_BinaryToNative(b *testing.B) {
+	record := model{
+		Age:     20,
+		Name:    "bob",
+		Address: "20. Classy Street",
+		Date:    time.Now(),
+		OtherNames: []address{
+			{Value: "wreckage"},
+			{Value: "moppers guild"},
+			{Value: "Is His always Faithful!"},
+		},
+	}
+
+	var codec RecordCodec
+	encoded, err := codec.NativeToBinary(record, nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var res model
+		codec.BinaryToNative(encoded, &res)
+	}
+	b.StopTimer()
+}
+
+func BenchmarkRecordCodec_JSONBinaryToNative(b *testing.B) {
+	record := model{
+		Age:     20,
+		Name:    "bob",
+		Address: "20. Classy Street",
+		Date:    time.Now(),
+		OtherNames: []address{
+			{Value: "wreckage"},
+			{Value: "moppers guild"},
+			{Value: "Is His always Faithful!"},
+		},
+	}
+
+	encoded, err := json.Marshal(record)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var res model
+		json.Unmarshal(encoded, &res)
+	}
+	b.StopTimer()
+}
+
+func BenchmarkRecordCodec_NativeToBinary_Parallel(b *testing.B) {
+	record := model{
+		Age:     20,
+		Name:    "bob",
+		Address: "20. Classy Street",
+		Date:    time.Now(),
+		OtherNames: []address{
+			{Value: "wreckage"},
+			{Value: "moppers guild"},
+			{Value: "Is His always Faithful!"},
+		},
+	}
+
+	var codec RecordCodec
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		var buf []byte
+		for pb.Next() {
+			buf, _ = codec.NativeToBinary(record, buf[:0])
+		}
+	})
+	b.StopTimer()
+}
+
+func BenchmarkListCodec_BinaryToNative_Expanding(b *testing.B) {
+	record := make([]model, 0, 1000)
+
+	for i := 0; i < 1000; i++ {
+		record = append(record, model{
+			Age:     20,
+			Name:    "bob",
+			Address: "20. Classy Street",
+			Date:    time.Now(),
+			OtherNames: []address{
+				{Value: "wreckage"},
+				{Value: "moppers guild"},
+				{Value: "Is His always Faithful!"},
+			},
+		})
+	}
+
+	var codec ListCodec
+	encoded, err := codec.NativeToBinary(record, nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		codec.BinaryToNative(encoded, []model{})
+	}
+	b.StopTimer()
+}
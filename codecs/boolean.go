@@ -21,16 +21,16 @@ const (
 type BooleanCodec struct{}
 
 func (BooleanCodec) BinaryToNative(b []byte) (interface{}, voxa.FieldID, error) {
-	if len(b) != 3 {
+	atom, id, data, err := ReadHeader(b)
+	if err != nil || len(data) != 1 {
 		return nil, 0, errors.New("byte slice must be of length 2")
 	}
 
-	id := voxa.FieldID(b[1])
-	if voxa.Atom(b[0]) != voxa.Boolean {
+	if atom != voxa.Boolean {
 		return nil, id, errors.New("byte slice must have supported type marker")
 	}
 
-	switch b[2] {
+	switch data[0] {
 	case off:
 		return false, id, nil
 	case on:
@@ -43,9 +43,12 @@ func (BooleanCodec) BinaryToNative(b []byte) (interface{}, voxa.FieldID, error)
 func (BooleanCodec) NativeToBinary(b interface{}, f voxa.FieldID, c []byte) ([]byte, error) {
 	if flag, ok := b.(bool); ok {
 		if flag {
-			return append(c, byte(voxa.Boolean), byte(f), on), nil
+			return append(AppendHeader(c, voxa.Boolean, f), on), nil
 		}
-		return append(c, byte(voxa.Boolean), byte(f), off), nil
+		return append(AppendHeader(c, voxa.Boolean, f), off), nil
+	}
+	if flag, ok := unnamed(b); ok {
+		return BooleanCodec{}.NativeToBinary(flag, f, c)
 	}
 
 	return nil, errors.New("type is not a bool/boolean")
@@ -80,5 +83,8 @@ func (BooleanCodec) NativeToTextual(b interface{}, c []byte) ([]byte, error) {
 		}
 		return append(c, "false"...), nil
 	}
+	if flag, ok := unnamed(b); ok {
+		return BooleanCodec{}.NativeToTextual(flag, c)
+	}
 	return nil, errors.New("type is not a bool/boolean")
 }
@@ -11,21 +11,23 @@ var _ voxa.Codec = BytesCodec{}
 type BytesCodec struct{}
 
 func (BytesCodec) BinaryToNative(b []byte) (interface{}, voxa.FieldID, error) {
-	if len(b) < 3 {
-		return nil, 0, errors.New("byte slice must be of length 2")
+	// an empty value has no data after the Atom and FieldID.
+	atom, id, data, err := ReadHeader(b)
+	if err != nil {
+		return nil, 0, err
 	}
 
-	id := voxa.FieldID(b[1])
-	if voxa.Atom(b[0]) != voxa.Bytes {
+	if atom != voxa.Bytes {
 		return nil, id, errors.New("byte slice must have supported type marker")
 	}
 
-	return b[2:], id, nil
+	// the data is copied, as provided byte slice may be reused by the caller.
+	return append([]byte{}, data...), id, nil
 }
 
 func (BytesCodec) NativeToBinary(b interface{}, id voxa.FieldID, c []byte) ([]byte, error) {
 	if bu, ok := b.([]byte); ok {
-		return append(append(c, byte(voxa.Bytes), byte(id)), bu...), nil
+		return append(AppendHeader(c, voxa.Bytes, id), bu...), nil
 	}
 
 	return nil, errors.New("type is not a []byte")
@@ -51,21 +53,21 @@ var _ voxa.Codec = ByteCodec{}
 type ByteCodec struct{}
 
 func (ByteCodec) BinaryToNative(b []byte) (interface{}, voxa.FieldID, error) {
-	if len(b) != 3 {
+	atom, id, data, err := ReadHeader(b)
+	if err != nil || len(data) != 1 {
 		return nil, 0, errors.New("byte slice must be of length 2")
 	}
 
-	id := voxa.FieldID(b[1])
-	if voxa.Atom(b[0]) != voxa.Boolean {
+	if atom != voxa.Boolean {
 		return nil, id, errors.New("byte slice must have supported type marker")
 	}
 
-	return b[2], id, nil
+	return data[0], id, nil
 }
 
 func (ByteCodec) NativeToBinary(b interface{}, id voxa.FieldID, c []byte) ([]byte, error) {
 	if bu, ok := b.(byte); ok {
-		return append(c, byte(voxa.Bit), byte(id), bu), nil
+		return append(AppendHeader(c, voxa.Bit, id), bu), nil
 	}
 
 	return nil, errors.New("type is not a byte")
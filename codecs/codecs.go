@@ -5,6 +5,7 @@ import (
 	"errors"
 	"math"
 	"math/bits"
+	"reflect"
 	"sync"
 )
 
@@ -20,22 +21,75 @@ var (
 
 	// ErrDecodeFailed is returned when decoding a byte slice into native type failed.
 	ErrDecodeFailed = errors.New("failed to decode value")
+
+	// ErrUnexpectedAtom is returned when a frame holds an Atom which can not
+	// be decoded into the destination type.
+	ErrUnexpectedAtom = errors.New("unexpected atom for type")
+
+	// ErrTrailingData is returned when the byte slice being decoded holds
+	// more bytes after it's frame.
+	ErrTrailingData = errors.New("invalid data, bytes after top level frame")
 )
 
+//******************************************
+// Named Types
+//******************************************
+
+// kindTypes holds the unnamed type of every scalar kind.
+var kindTypes = map[reflect.Kind]reflect.Type{
+	reflect.Bool:    reflect.TypeOf(false),
+	reflect.Int:     reflect.TypeOf(int(0)),
+	reflect.Int8:    reflect.TypeOf(int8(0)),
+	reflect.Int16:   reflect.TypeOf(int16(0)),
+	reflect.Int32:   reflect.TypeOf(int32(0)),
+	reflect.Int64:   reflect.TypeOf(int64(0)),
+	reflect.Uint:    reflect.TypeOf(uint(0)),
+	reflect.Uint8:   reflect.TypeOf(uint8(0)),
+	reflect.Uint16:  reflect.TypeOf(uint16(0)),
+	reflect.Uint32:  reflect.TypeOf(uint32(0)),
+	reflect.Uint64:  reflect.TypeOf(uint64(0)),
+	reflect.Float32: reflect.TypeOf(float32(0)),
+	reflect.Float64: reflect.TypeOf(float64(0)),
+	reflect.String:  reflect.TypeOf(""),
+
+	reflect.Complex64:  reflect.TypeOf(complex64(0)),
+	reflect.Complex128: reflect.TypeOf(complex128(0)),
+}
+
+// unnamed returns provided value of a named scalar type, such as a
+// time.Duration or a `type Status int32`, converted into the unnamed type of
+// it's kind. It returns false for values of unnamed or non scalar types.
+func unnamed(v interface{}) (interface{}, bool) {
+	val := reflect.ValueOf(v)
+	if !val.IsValid() {
+		return nil, false
+	}
+
+	base, ok := kindTypes[val.Kind()]
+	if !ok || val.Type() == base {
+		return nil, false
+	}
+
+	return val.Convert(base).Interface(), true
+}
+
 //******************************************
 // DefaultCodec Registry
 //******************************************
 
 var (
-	intCodec    IntCodec
-	floatCodec  FloatCodec
-	textCodec   TextCodec
-	bytesCodec  BytesCodec
-	byteCodec   ByteCodec
-	boolCodec   BooleanCodec
-	listCodec   ListCodec
-	recordCodec RecordCodec
-	timeCodec   TimeCodec
+	intCodec     IntCodec
+	floatCodec   FloatCodec
+	complexCodec ComplexCodec
+	textCodec    TextCodec
+	bytesCodec   BytesCodec
+	byteCodec    ByteCodec
+	boolCodec    BooleanCodec
+	listCodec    ListCodec
+	recordCodec  RecordCodec
+	mapCodec     MapCodec
+	timeCodec    TimeCodec
+	headerCodec  HeaderCodec
 )
 
 //******************************************
@@ -227,6 +281,18 @@ func EncodeVarInt64(x uint64) []byte {
 	return buf[0:n]
 }
 
+// EncodeZigZag64 maps provided signed integer to an unsigned integer using
+// zigzag encoding, where small negative and positive numbers both map to
+// small unsigned numbers, so they are encoded with few bytes as a varint.
+func EncodeZigZag64(x int64) uint64 {
+	return uint64(x<<1) ^ uint64(x>>63)
+}
+
+// DecodeZigZag64 reverses the mapping done by EncodeZigZag64.
+func DecodeZigZag64(x uint64) int64 {
+	return int64(x>>1) ^ -int64(x&1)
+}
+
 // DecodeVarInt32 encodes uint32 into a byte slice
 // using EncodeVarInt64 after turing uint32 into uin64.
 func DecodeVarInt32(b []byte) (uint32, int) {
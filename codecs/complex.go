@@ -0,0 +1,108 @@
+package codecs
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"strconv"
+
+	"github.com/wirekit/voxa"
+)
+
+var _ voxa.Codec = ComplexCodec{}
+
+// ComplexCodec encodes complex64 and complex128 values as a frame with
+// format:
+//
+//	[Complex64|Complex128][FieldID][Real][Imaginary]
+//
+// where both parts are big endian floats of 4 bytes for Complex64 and 8
+// bytes for Complex128, as they are written within a Packed frame.
+type ComplexCodec struct{}
+
+func (ComplexCodec) BinaryToNative(b []byte) (interface{}, voxa.FieldID, error) {
+	atom, id, val, err := ReadHeader(b)
+	if err != nil {
+		return nil, 0, errors.New("byte slice must be of length 2")
+	}
+
+	switch atom {
+	case voxa.Complex64:
+		if len(val) != 8 {
+			return nil, id, ErrDecodeFailed
+		}
+
+		value, _ := nextComplex(atom, val)
+		return complex64(value), id, nil
+	case voxa.Complex128:
+		if len(val) != 16 {
+			return nil, id, ErrDecodeFailed
+		}
+
+		value, _ := nextComplex(atom, val)
+		return value, id, nil
+	default:
+		return nil, id, errors.New("byte slice must have supported type marker")
+	}
+}
+
+func (ComplexCodec) NativeToBinary(b interface{}, id voxa.FieldID, c []byte) ([]byte, error) {
+	switch val := b.(type) {
+	case complex64:
+		return AppendPackedComplex(AppendHeader(c, voxa.Complex64, id), voxa.Complex64, complex128(val)), nil
+	case complex128:
+		return AppendPackedComplex(AppendHeader(c, voxa.Complex128, id), voxa.Complex128, val), nil
+	}
+
+	if val, ok := unnamed(b); ok {
+		return ComplexCodec{}.NativeToBinary(val, id, c)
+	}
+
+	return nil, errors.New("type is not a complex64/complex128")
+}
+
+func (ComplexCodec) MarshalTextualToNative(_ []byte, _ interface{}) error {
+	return ErrNotSupported
+}
+
+func (ComplexCodec) TextualToNative(b []byte) (interface{}, error) {
+	return strconv.ParseComplex(string(b), 128)
+}
+
+func (ComplexCodec) NativeToTextual(b interface{}, c []byte) ([]byte, error) {
+	switch val := b.(type) {
+	case complex64:
+		return append(c, strconv.FormatComplex(complex128(val), 'f', 10, 64)...), nil
+	case complex128:
+		return append(c, strconv.FormatComplex(val, 'f', 10, 128)...), nil
+	}
+
+	if val, ok := unnamed(b); ok {
+		return ComplexCodec{}.NativeToTextual(val, c)
+	}
+	return nil, errors.New("type is not a complex64/complex128")
+}
+
+// AppendPackedComplex appends provided value as a packed value of provided
+// complex element Atom into provided byte slice.
+func AppendPackedComplex(c []byte, elem voxa.Atom, v complex128) []byte {
+	if elem == voxa.Complex64 {
+		return AppendPackedFloat(AppendPackedFloat(c, voxa.Float32, real(v)), voxa.Float32, imag(v))
+	}
+	return AppendPackedFloat(AppendPackedFloat(c, voxa.Float64, real(v)), voxa.Float64, imag(v))
+}
+
+// nextComplex returns the first of provided values of provided complex
+// element Atom and the values following it, which must hold at least a
+// value.
+func nextComplex(elem voxa.Atom, values []byte) (complex128, []byte) {
+	if elem == voxa.Complex64 {
+		re := math.Float32frombits(binary.BigEndian.Uint32(values))
+		im := math.Float32frombits(binary.BigEndian.Uint32(values[4:]))
+		return complex(float64(re), float64(im)), values[8:]
+	}
+
+	re := math.Float64frombits(binary.BigEndian.Uint64(values))
+	im := math.Float64frombits(binary.BigEndian.Uint64(values[8:]))
+	return complex(re, im), values[16:]
+}
@@ -0,0 +1,161 @@
+package codecs_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/influx6/faux/tests"
+	"github.com/wirekit/voxa"
+	"github.com/wirekit/voxa/codecs"
+)
+
+var (
+	complexValue       = complex64(1 + 2i)
+	complexTextual     = []byte("(1.0000000000+2.0000000000i)")
+	goodEncodedComplex = []byte{byte(voxa.Complex64), 1, 0x3f, 0x80, 0, 0, 0x40, 0, 0, 0}
+)
+
+func TestComplexCodec_NativeToBinary(t *testing.T) {
+	var codec codecs.ComplexCodec
+	encoded, err := codec.NativeToBinary(complexValue, 1, []byte{})
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded complex value")
+	}
+	tests.Passed("Should have successfully encoded complex value")
+
+	if !bytes.Equal(encoded, goodEncodedComplex) {
+		tests.Info("Received: %#v", encoded)
+		tests.Info("Expected: %#v", goodEncodedComplex)
+		tests.Failed("Should have matched encoded complex value with expected")
+	}
+	tests.Passed("Should have matched encoded complex value with expected")
+}
+
+func TestComplexCodec_BinaryToNative(t *testing.T) {
+	var codec codecs.ComplexCodec
+	decoded, _, err := codec.BinaryToNative(goodEncodedComplex)
+	if err != nil {
+		tests.FailedWithError(err, "expected no error with decoding")
+	}
+
+	if decoded != complexValue {
+		tests.Info("Received: %#v", decoded)
+		tests.Info("Expected: %#v", complexValue)
+		tests.Failed("Should have received expected decoded value")
+	}
+	tests.Passed("Should have received expected decoded value")
+
+	if _, _, err := codec.BinaryToNative(goodEncodedComplex[:6]); err == nil {
+		tests.Failed("Should have failed to decode truncated complex value")
+	}
+	tests.Passed("Should have failed to decode truncated complex value")
+}
+
+func TestComplexCodec_Textual(t *testing.T) {
+	var codec codecs.ComplexCodec
+	encoded, err := codec.NativeToTextual(complexValue, []byte{})
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded complex value")
+	}
+
+	if !bytes.Equal(encoded, complexTextual) {
+		tests.Info("Received: %+q", encoded)
+		tests.Info("Expected: %+q", complexTextual)
+		tests.Failed("Should have matched encoded complex value with expected")
+	}
+	tests.Passed("Should have matched encoded complex value with expected")
+
+	decoded, err := codec.TextualToNative(complexTextual)
+	if err != nil {
+		tests.FailedWithError(err, "expected no error with decoding")
+	}
+
+	if decoded != complex128(complexValue) {
+		tests.Info("Received: %#v", decoded)
+		tests.Failed("Should have received expected decoded value")
+	}
+	tests.Passed("Should have received expected decoded value")
+}
+
+func TestComplexCodec_RoundTrip(t *testing.T) {
+	type phasor complex128
+
+	type signal struct {
+		Gain    complex64     `id:"1"`
+		Phase   phasor        `id:"2"`
+		Samples []complex128  `id:"3"`
+		Window  [2]complex64  `id:"4"`
+		Offset  *complex128   `id:"5"`
+		Note    interface{}   `id:"6"`
+		Default complex128    `id:"7" default:"(1+1i)"`
+		Nested  [][]complex64 `id:"8"`
+	}
+
+	offset := complex(-0.5, 0.25)
+	record := signal{
+		Gain:    complexValue,
+		Phase:   phasor(complex(3.14159, -2.71828)),
+		Samples: []complex128{1 + 1i, -2.5 - 0.5i, 1e300i},
+		Window:  [2]complex64{1, 1i},
+		Offset:  &offset,
+		Note:    complex128(4 - 4i),
+		Default: 2i,
+		Nested:  [][]complex64{{1i}, {}},
+	}
+
+	encoded, err := voxa.Marshal(record)
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded complex values")
+	}
+	tests.Passed("Should have successfully encoded complex values")
+
+	var res signal
+	if err := voxa.Unmarshal(encoded, &res); err != nil {
+		tests.FailedWithError(err, "Should have successfully decoded complex values")
+	}
+	tests.Passed("Should have successfully decoded complex values")
+
+	if !reflect.DeepEqual(res, record) {
+		tests.Info("Received: %#v", res)
+		tests.Info("Expected: %#v", record)
+		tests.Failed("Should have matching complex values between input and res")
+	}
+	tests.Passed("Should have matching complex values between input and res")
+
+	var samples interface{}
+	if err := voxa.Unmarshal(mustMarshal([]complex64{1i, 2}), &samples); err != nil {
+		tests.FailedWithError(err, "Should have successfully decoded packed complex values into interface")
+	}
+
+	if !reflect.DeepEqual(samples, []interface{}{complex64(1i), complex64(2)}) {
+		tests.Info("Received: %#v", samples)
+		tests.Failed("Should have decoded packed complex values as complex64")
+	}
+	tests.Passed("Should have decoded packed complex values as complex64")
+
+	var widened []complex128
+	if err := voxa.Unmarshal(mustMarshal([]complex64{1i, 2}), &widened); err != nil {
+		tests.FailedWithError(err, "Should have successfully decoded complex64 values into complex128")
+	}
+
+	if !reflect.DeepEqual(widened, []complex128{1i, 2}) {
+		tests.Info("Received: %#v", widened)
+		tests.Failed("Should have converted complex64 values into complex128")
+	}
+	tests.Passed("Should have converted complex64 values into complex128")
+
+	var floats []float64
+	if err := voxa.Unmarshal(mustMarshal([]complex64{1i, 2}), &floats); err == nil {
+		tests.Failed("Should have failed to decode complex values into floats")
+	}
+	tests.Passed("Should have failed to decode complex values into floats")
+}
+
+func mustMarshal(v interface{}) []byte {
+	encoded, err := voxa.Marshal(v)
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded value")
+	}
+	return encoded
+}
@@ -0,0 +1,513 @@
+package codecs
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+
+	"github.com/wirekit/voxa"
+)
+
+var _ voxa.Engine = Engine{}
+
+func init() {
+	voxa.RegisterEngine(Engine{})
+}
+
+// Engine implements the voxa.Engine, routing values to the RecordCodec,
+// MapCodec, ListCodec or scalar codec matching their reflect.Kind when
+// encoding, and matching the Atom of the frame when decoding.
+//
+// Every value is encoded as a frame: `[Length VarInt][Atom][FieldID VarInt][Data...]`.
+type Engine struct{}
+
+// Marshal encodes provided value into a voxa frame with a FieldID of 0.
+func (Engine) Marshal(b interface{}, c []byte) ([]byte, error) {
+	item := reflect.ValueOf(b)
+	if !item.IsValid() || (item.Kind() == reflect.Ptr && item.IsNil()) {
+		return c, errors.New("can not marshal nil value")
+	}
+
+	encoded, err := nativeItemToBinary(b, 0, c)
+	if err == ErrSkipErr {
+		return c, &voxa.EncodeError{Type: item.Type(), Err: ErrUnknownType}
+	}
+	if err != nil {
+		return c, encodeError(err, "", item.Type())
+	}
+
+	return encoded, nil
+}
+
+// Unmarshal decodes the voxa frame held by provided byte slice into the
+// value pointed to by target, enforcing provided limits. It fails with
+// ErrTrailingData when the byte slice holds more bytes after the frame.
+func (Engine) Unmarshal(b []byte, target interface{}, limits voxa.Limits) error {
+	dest := reflect.ValueOf(target)
+	if dest.Kind() != reflect.Ptr || dest.IsNil() {
+		return ErrMustBePointer
+	}
+
+	d := newDecodeState(b, limits)
+
+	frame, content, rest, err := NextFrame(b)
+	if err != nil {
+		return d.fail(b, err)
+	}
+
+	if len(rest) > 0 {
+		return d.fail(rest, ErrTrailingData)
+	}
+
+	return d.decodeValue(frame, content, dest.Elem())
+}
+
+// DecodeAny decodes the voxa frame held by provided byte slice into its
+// generic value, enforcing provided limits. It fails with ErrTrailingData as
+// Unmarshal does.
+func (Engine) DecodeAny(b []byte, limits voxa.Limits) (interface{}, error) {
+	d := newDecodeState(b, limits)
+	d.generic = true
+
+	frame, content, rest, err := NextFrame(b)
+	if err != nil {
+		return nil, d.fail(b, err)
+	}
+
+	if len(rest) > 0 {
+		return nil, d.fail(rest, ErrTrailingData)
+	}
+
+	var value interface{}
+	if err := d.decodeValue(frame, content, reflect.ValueOf(&value).Elem()); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// decodeState holds the state of a single decode, which is shared by all
+// nested values. Generic decodes, as done by DecodeAny, key records by
+// voxa.FieldID and decode unions of unregistered types into their value.
+type decodeState struct {
+	data    []byte
+	limits  voxa.Limits
+	depth   int
+	generic bool
+}
+
+func newDecodeState(data []byte, limits voxa.Limits) *decodeState {
+	return &decodeState{data: data, limits: limits}
+}
+
+// offset returns the position of provided byte slice within the decoded
+// data, which every frame is sliced from.
+func (d *decodeState) offset(b []byte) int64 {
+	return int64(cap(d.data) - cap(b))
+}
+
+// fail returns provided error as a *voxa.DecodeError located at provided
+// byte slice, unless it already is one.
+func (d *decodeState) fail(b []byte, err error) error {
+	if _, ok := err.(*voxa.DecodeError); ok {
+		return err
+	}
+	return &voxa.DecodeError{Offset: d.offset(b), Err: err}
+}
+
+// enter increases the nesting depth when decoding the content of a record,
+// list or map, failing when it exceeds the MaxDepth limit. Every call must
+// be paired with a call to leave.
+func (d *decodeState) enter() error {
+	d.depth++
+	if d.depth > d.limits.MaxDepth {
+		return &voxa.LimitError{Limit: "MaxDepth", Value: int64(d.depth), Max: int64(d.limits.MaxDepth)}
+	}
+	return nil
+}
+
+func (d *decodeState) leave() {
+	d.depth--
+}
+
+// unmarshal decodes provided frame with provided Unmarshaler, passing it
+// the limits left to the current depth when it is a
+// voxa.LimitedUnmarshaler.
+func (d *decodeState) unmarshal(unmarshaler voxa.Unmarshaler, frame []byte) error {
+	if limited, ok := unmarshaler.(voxa.LimitedUnmarshaler); ok {
+		limits := d.limits
+		limits.MaxDepth -= d.depth
+		return limited.UnmarshalVoxaLimits(frame, limits)
+	}
+	return unmarshaler.UnmarshalVoxa(frame)
+}
+
+// checkBlockCount fails when provided number of items exceeds the
+// MaxBlockCount limit.
+func (d *decodeState) checkBlockCount(count int) error {
+	if int64(count) > d.limits.MaxBlockCount {
+		return &voxa.LimitError{Limit: "MaxBlockCount", Value: int64(count), Max: d.limits.MaxBlockCount}
+	}
+	return nil
+}
+
+// checkCount fails when provided number of items exceeds the MaxBlockCount
+// limit, or the limit for the width of provided element type.
+func (d *decodeState) checkCount(count int, elem reflect.Type) error {
+	if err := d.checkBlockCount(count); err != nil {
+		return err
+	}
+
+	var limit string
+	var max int64
+	switch elem.Kind() {
+	case reflect.Bool, reflect.Int8, reflect.Uint8:
+		limit, max = "MaxIBU8Count", d.limits.MaxIBU8Count
+	case reflect.Int16, reflect.Uint16:
+		limit, max = "MaxIBU16Count", d.limits.MaxIBU16Count
+	case reflect.Int32, reflect.Uint32, reflect.Float32:
+		limit, max = "MaxIBU32Count", d.limits.MaxIBU32Count
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64, reflect.Float64, reflect.Complex64:
+		limit, max = "MaxIBU64Count", d.limits.MaxIBU64Count
+	case reflect.Complex128:
+		limit, max = "MaxICU128Count", d.limits.MaxICU128Count
+	default:
+		return nil
+	}
+
+	if int64(count) > max {
+		return &voxa.LimitError{Limit: limit, Value: int64(count), Max: max}
+	}
+	return nil
+}
+
+// decodeValue decodes provided frame into dest, which must be settable.
+// content is the frame without it's length prefix. Errors are returned as
+// a *voxa.DecodeError located at the frame which failed.
+func (d *decodeState) decodeValue(frame []byte, content []byte, dest reflect.Value) error {
+	err := d.decodeFrame(frame, content, dest)
+	if err == nil {
+		return nil
+	}
+
+	if _, ok := err.(*voxa.DecodeError); ok {
+		return err
+	}
+	return &voxa.DecodeError{Offset: d.offset(frame), Actual: voxa.Atom(content[0]), Err: err}
+}
+
+func (d *decodeState) decodeFrame(frame []byte, content []byte, dest reflect.Value) error {
+	if !dest.CanSet() {
+		return ErrValueUnsettable
+	}
+
+	if size := int64(len(content)); size > d.limits.MaxBlockSize {
+		return &voxa.LimitError{Limit: "MaxBlockSize", Value: size, Max: d.limits.MaxBlockSize}
+	}
+
+	// a Null frame leaves dest at it's zero value, such as a nil pointer.
+	if voxa.Atom(content[0]) == voxa.Null {
+		dest.Set(reflect.Zero(dest.Type()))
+		return nil
+	}
+
+	// the value of a Union frame is decoded in place of the Union.
+	if voxa.Atom(content[0]) == voxa.Union {
+		return d.decodeUnion(content, dest)
+	}
+
+	// described records are matched by name, which the Unmarshaler of a
+	// record type can not do.
+	if voxa.Atom(content[0]) == voxa.Schema && dest.Kind() != reflect.Ptr {
+		return recordCodec.decodeDescribed(d, content, dest)
+	}
+
+	if dest.Kind() != reflect.Ptr && dest.CanAddr() {
+		if unmarshaler, ok := dest.Addr().Interface().(voxa.Unmarshaler); ok {
+			// errors of a Unmarshaler are located at it's frame, even
+			// when they are a *voxa.DecodeError of their own.
+			if err := d.unmarshal(unmarshaler, frame); err != nil {
+				return &voxa.DecodeError{Offset: d.offset(frame), Actual: voxa.Atom(content[0]), Err: err}
+			}
+			return nil
+		}
+	}
+
+	switch dest.Kind() {
+	case reflect.Ptr:
+		if dest.IsNil() {
+			dest.Set(reflect.New(dest.Type().Elem()))
+		}
+		return d.decodeValue(frame, content, dest.Elem())
+	case reflect.Interface:
+		if dest.NumMethod() == 0 {
+			return d.decodeInterface(frame, content, dest)
+		}
+	}
+
+	atom := voxa.Atom(content[0])
+	switch atom {
+	case voxa.Record:
+		switch dest.Kind() {
+		case reflect.Struct:
+			return recordCodec.decodeStruct(d, content, dest, nil)
+		case reflect.Map:
+			return recordCodec.decodeMap(d, content, dest)
+		}
+	case voxa.Map:
+		if dest.Kind() == reflect.Map {
+			return mapCodec.decodeMap(d, content, dest)
+		}
+	case voxa.List:
+		if dest.Kind() == reflect.Array {
+			return listCodec.decodeArray(d, content, dest)
+		}
+
+		if dest.Kind() == reflect.Slice {
+			list, err := listCodec.appendList(d, content, reflect.MakeSlice(dest.Type(), 0, 0))
+			if err != nil {
+				return err
+			}
+
+			dest.Set(list)
+			return nil
+		}
+	case voxa.Packed:
+		if dest.Kind() == reflect.Array || dest.Kind() == reflect.Slice {
+			return listCodec.decodePacked(d, frame, content, dest)
+		}
+	case voxa.Bytes:
+		if isByteArray(dest.Type()) {
+			return decodeByteArray(content, dest)
+		}
+
+		if dest.CanAddr() {
+			if unmarshaler, ok := dest.Addr().Interface().(encoding.BinaryUnmarshaler); ok {
+				value, _, err := bytesCodec.BinaryToNative(content)
+				if err != nil {
+					return err
+				}
+				return unmarshaler.UnmarshalBinary(value.([]byte))
+			}
+		}
+
+		if isByteSlice(dest.Type()) {
+			return decodeByteSlice(content, dest)
+		}
+	case voxa.Text:
+		if !dest.CanAddr() {
+			break
+		}
+
+		if unmarshaler, ok := dest.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			value, _, err := textCodec.BinaryToNative(content)
+			if err != nil {
+				return err
+			}
+			return unmarshaler.UnmarshalText([]byte(value.(string)))
+		}
+	}
+
+	codec, ok := codecForAtom(atom)
+	if !ok {
+		return d.mismatch(frame, atom, dest.Type())
+	}
+
+	value, _, err := codec.BinaryToNative(content)
+	if err != nil {
+		return err
+	}
+
+	if err := assignValue(dest, value); err != nil {
+		// values which do not fit into dest keep the reason they failed,
+		// rather than reporting the atom as unexpected for dest's type.
+		if errors.Is(err, ErrUnexpectedAtom) {
+			return d.fail(frame, err)
+		}
+		return d.mismatch(frame, atom, dest.Type())
+	}
+	return nil
+}
+
+// mismatch returns a *voxa.DecodeError for a frame of provided Atom which
+// can not be decoded into provided type.
+func (d *decodeState) mismatch(frame []byte, atom voxa.Atom, t reflect.Type) error {
+	return &voxa.DecodeError{
+		Offset:   d.offset(frame),
+		Expected: atomFor(t),
+		Actual:   atom,
+		Err:      fmt.Errorf("can not decode %s into %q: %w", atom, t, ErrUnexpectedAtom),
+	}
+}
+
+// decodeInterface decodes provided frame into the empty interface dest,
+// using a []interface{} for lists and packed values and a
+// map[interface{}]interface{} for records and maps. Packed values hold the
+// types the scalar codecs decode into. Described records are decoded by
+// decodeDescribed.
+func (d *decodeState) decodeInterface(frame []byte, content []byte, dest reflect.Value) error {
+	var value reflect.Value
+	switch voxa.Atom(content[0]) {
+	case voxa.Record:
+		if d.generic {
+			value = reflect.New(reflect.TypeOf(map[voxa.FieldID]interface{}{})).Elem()
+			break
+		}
+		value = reflect.New(reflect.TypeOf(map[interface{}]interface{}{})).Elem()
+	case voxa.Map:
+		value = reflect.New(reflect.TypeOf(map[interface{}]interface{}{})).Elem()
+	case voxa.List, voxa.Packed:
+		value = reflect.New(reflect.TypeOf([]interface{}{})).Elem()
+	default:
+		codec, ok := codecForAtom(voxa.Atom(content[0]))
+		if !ok {
+			return ErrUnknownType
+		}
+
+		decoded, _, err := codec.BinaryToNative(content)
+		if err != nil {
+			return err
+		}
+
+		dest.Set(reflect.ValueOf(decoded))
+		return nil
+	}
+
+	if err := d.decodeValue(frame, content, value); err != nil {
+		return err
+	}
+
+	dest.Set(value)
+	return nil
+}
+
+// codecForAtom returns the scalar codec responsible for provided Atom.
+func codecForAtom(atom voxa.Atom) (voxa.Codec, bool) {
+	switch atom {
+	case voxa.Text:
+		return textCodec, true
+	case voxa.Time:
+		return timeCodec, true
+	case voxa.Bytes:
+		return bytesCodec, true
+	case voxa.Boolean:
+		return boolCodec, true
+	case voxa.Float32, voxa.Float64:
+		return floatCodec, true
+	case voxa.Complex64, voxa.Complex128:
+		return complexCodec, true
+	case voxa.Int, voxa.UInt, voxa.UInt8, voxa.UInt16, voxa.UInt32, voxa.UInt64,
+		voxa.Int8, voxa.Int16, voxa.Int32, voxa.Int64, voxa.SInt, voxa.SInt32, voxa.SInt64:
+		return intCodec, true
+	}
+	return nil, false
+}
+
+// assignValue sets provided decoded value into dest, converting between
+// numeric types of differing width and into named types, such as a
+// time.Duration, where needed. Numbers which do not fit into dest fail
+// rather than being truncated.
+func assignValue(dest reflect.Value, value interface{}) error {
+	if !dest.CanSet() {
+		return ErrValueUnsettable
+	}
+
+	val := reflect.ValueOf(value)
+	if val.Type().AssignableTo(dest.Type()) {
+		dest.Set(val)
+		return nil
+	}
+
+	if isNumericKind(val.Kind()) && isNumericKind(dest.Kind()) {
+		return setNumber(dest, val)
+	}
+
+	if isComplexKind(val.Kind()) && isComplexKind(dest.Kind()) {
+		if dest.OverflowComplex(val.Complex()) {
+			return fmt.Errorf("%v does not fit into %q: %w", val, dest.Type(), ErrUnexpectedAtom)
+		}
+		dest.Set(val.Convert(dest.Type()))
+		return nil
+	}
+
+	if val.Kind() == dest.Kind() && val.Type().ConvertibleTo(dest.Type()) {
+		dest.Set(val.Convert(dest.Type()))
+		return nil
+	}
+
+	return fmt.Errorf("can not assign %q to %q", val.Type(), dest.Type())
+}
+
+// setNumber sets provided numeric value into the numeric dest, failing
+// with ErrUnexpectedAtom when the value does not fit into dest, such as 1000
+// into an int8, a negative value into an unsigned type or a fractional float
+// into an integer.
+func setNumber(dest reflect.Value, val reflect.Value) error {
+	var overflow bool
+	switch {
+	case isIntKind(val.Kind()):
+		n := val.Int()
+		switch {
+		case isIntKind(dest.Kind()):
+			overflow = dest.OverflowInt(n)
+		case isUintKind(dest.Kind()):
+			overflow = n < 0 || dest.OverflowUint(uint64(n))
+		}
+	case isUintKind(val.Kind()):
+		n := val.Uint()
+		switch {
+		case isIntKind(dest.Kind()):
+			overflow = n > math.MaxInt64 || dest.OverflowInt(int64(n))
+		case isUintKind(dest.Kind()):
+			overflow = dest.OverflowUint(n)
+		}
+	default:
+		f := val.Float()
+		switch {
+		case isIntKind(dest.Kind()):
+			overflow = f != math.Trunc(f) || f < -(1<<63) || f >= 1<<63 || dest.OverflowInt(int64(f))
+		case isUintKind(dest.Kind()):
+			overflow = f != math.Trunc(f) || f < 0 || f >= 1<<64 || dest.OverflowUint(uint64(f))
+		default:
+			overflow = dest.OverflowFloat(f)
+		}
+	}
+
+	if overflow {
+		return fmt.Errorf("%v does not fit into %q: %w", val, dest.Type(), ErrUnexpectedAtom)
+	}
+
+	dest.Set(val.Convert(dest.Type()))
+	return nil
+}
+
+func isIntKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	}
+	return false
+}
+
+func isUintKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+func isNumericKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func isComplexKind(k reflect.Kind) bool {
+	return k == reflect.Complex64 || k == reflect.Complex128
+}
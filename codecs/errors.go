@@ -0,0 +1,58 @@
+package codecs
+
+import (
+	"reflect"
+
+	"github.com/wirekit/voxa"
+)
+
+// withPath prefixes provided path segment, a field name or an index such
+// as `[3]`, to the path of provided *voxa.DecodeError or *voxa.EncodeError.
+// All other errors are returned as they are.
+func withPath(err error, segment string) error {
+	switch e := err.(type) {
+	case *voxa.DecodeError:
+		e.Path = joinPath(segment, e.Path)
+	case *voxa.EncodeError:
+		e.Path = joinPath(segment, e.Path)
+	}
+	return err
+}
+
+// maxPathLength is the length after which the path of an error is collapsed
+// into it's outermost and innermost segments around a "...", hence errors of
+// deeply nested values stay short and are built in linear time.
+const maxPathLength = 256
+
+func joinPath(segment string, path string) string {
+	var joined string
+	switch {
+	case segment == "":
+		return path
+	case path == "":
+		return segment
+	case path[0] == '[':
+		joined = segment + path
+	default:
+		joined = segment + "." + path
+	}
+
+	if len(joined) > maxPathLength {
+		joined = joined[:maxPathLength/2] + "..." + joined[len(joined)-maxPathLength/2:]
+	}
+	return joined
+}
+
+// encodeError returns provided error as a *voxa.EncodeError for a value of
+// provided type at provided path segment, unless it already is one. The
+// ErrSkipErr is returned as it is.
+func encodeError(err error, segment string, t reflect.Type) error {
+	if err == ErrSkipErr {
+		return err
+	}
+
+	if _, ok := err.(*voxa.EncodeError); !ok {
+		err = &voxa.EncodeError{Type: t, Err: err}
+	}
+	return withPath(err, segment)
+}
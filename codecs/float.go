@@ -13,14 +13,12 @@ var _ voxa.Codec = FloatCodec{}
 type FloatCodec struct{}
 
 func (FloatCodec) BinaryToNative(b []byte) (interface{}, voxa.FieldID, error) {
-	if len(b) < 3 {
+	atom, id, val, err := ReadHeader(b)
+	if err != nil || len(val) == 0 {
 		return nil, 0, errors.New("byte slice must be of length 2")
 	}
 
-	id := voxa.FieldID(b[1])
-
-	val := b[2:]
-	switch voxa.Atom(b[0]) {
+	switch atom {
 	case voxa.Float32:
 		dl, n := DecodeVarInt32(val)
 		if n == 0 {
@@ -43,11 +41,14 @@ func (FloatCodec) BinaryToNative(b []byte) (interface{}, voxa.FieldID, error) {
 func (FloatCodec) NativeToBinary(b interface{}, id voxa.FieldID, c []byte) ([]byte, error) {
 	if val, ok := b.(float32); ok {
 		enc := EncodeVarInt32(EncodeFloat32(val))
-		return append(append(c, byte(voxa.Float32), byte(id)), enc...), nil
+		return append(AppendHeader(c, voxa.Float32, id), enc...), nil
 	}
 	if val, ok := b.(float64); ok {
 		enc := EncodeVarInt64(EncodeFloat64(val))
-		return append(append(c, byte(voxa.Float64), byte(id)), enc...), nil
+		return append(AppendHeader(c, voxa.Float64, id), enc...), nil
+	}
+	if val, ok := unnamed(b); ok {
+		return FloatCodec{}.NativeToBinary(val, id, c)
 	}
 
 	return nil, errors.New("type is not a float32/float64")
@@ -68,5 +69,8 @@ func (FloatCodec) NativeToTextual(b interface{}, c []byte) ([]byte, error) {
 	if val, ok := b.(float64); ok {
 		return strconv.AppendFloat(c, val, 'f', 10, 64), nil
 	}
+	if val, ok := unnamed(b); ok {
+		return FloatCodec{}.NativeToTextual(val, c)
+	}
 	return nil, errors.New("type is not a float32/float64")
 }
@@ -0,0 +1,339 @@
+package codecs
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/wirekit/voxa"
+)
+
+//******************************************
+// Frame Functions
+//******************************************
+
+// Every encoded value is a frame with format `[Length VarInt][Atom][FieldID VarInt][Data...]`,
+// where Length is the total length of the Atom, FieldID and Data. The
+// functions below read and write frames, and are used both within the codecs
+// and by code generated with voxagen.
+
+// ReserveFrame appends a single byte placeholder for the length of a new frame
+// into provided byte slice, returning the byte slice with new length and the
+// start of the frame, which must be passed to CloseFrame once the content of
+// the frame has been appended.
+func ReserveFrame(c []byte) ([]byte, int) {
+	return append(c, 0), len(c)
+}
+
+// CloseFrame writes the varint length of the frame content after the single
+// byte reserved at c[start], shifting the content when the length needs more
+// than a byte.
+func CloseFrame(c []byte, start int) []byte {
+	size := len(c) - start - 1
+	if size < 0x80 {
+		c[start] = byte(size)
+		return c
+	}
+
+	prefix := EncodeVarInt64(uint64(size))
+	c = append(c, prefix[1:]...)
+	copy(c[start+len(prefix):], c[start+1:start+1+size])
+	copy(c[start:], prefix)
+	return c
+}
+
+// AppendHeader appends the Atom and varint FieldID which start the content
+// of a frame into provided byte slice.
+func AppendHeader(c []byte, atom voxa.Atom, id voxa.FieldID) []byte {
+	return appendFieldID(append(c, byte(atom)), id)
+}
+
+// appendFieldID appends provided FieldID as a varint into provided byte
+// slice, which takes a single byte for ids below 128.
+func appendFieldID(c []byte, id voxa.FieldID) []byte {
+	for id >= 0x80 {
+		c = append(c, byte(id)|0x80)
+		id >>= 7
+	}
+	return append(c, byte(id))
+}
+
+// readFieldID returns the varint FieldID at the start of provided byte
+// slice and the bytes following it.
+func readFieldID(b []byte) (voxa.FieldID, []byte, error) {
+	if len(b) > 0 && b[0] < 0x80 {
+		return voxa.FieldID(b[0]), b[1:], nil
+	}
+
+	id, n := DecodeVarInt64(b)
+	if n == 0 || id > uint64(voxa.MaxFieldID) {
+		return 0, nil, ErrMalformedFieldID
+	}
+	return voxa.FieldID(id), b[n:], nil
+}
+
+// ReadHeader returns the Atom and FieldID which start provided frame content,
+// and the data following them.
+func ReadHeader(content []byte) (voxa.Atom, voxa.FieldID, []byte, error) {
+	if len(content) < 2 {
+		return voxa.Invalid, 0, nil, ErrInvalidDataSlice
+	}
+
+	id, data, err := readFieldID(content[1:])
+	if err != nil {
+		return voxa.Invalid, 0, nil, err
+	}
+	return voxa.Atom(content[0]), id, data, nil
+}
+
+// NextFrame splits the first frame from provided byte slice, returning the
+// frame with it's length prefix, the content of the frame starting from
+// it's Atom and the bytes remaining after the frame.
+func NextFrame(b []byte) (frame []byte, content []byte, rest []byte, err error) {
+	xl, read := DecodeVarInt64(b)
+	if xl == 0 {
+		return nil, nil, nil, ErrInvalidNoSize
+	}
+
+	// every frame must at least contain an Atom and FieldID.
+	if xl < 2 || uint64(len(b)-read) < xl {
+		return nil, nil, nil, ErrInvalidDataSlice
+	}
+
+	total := read + int(xl)
+	return b[:total], b[read:total], b[total:], nil
+}
+
+// AppendNull appends a Null frame marked with provided FieldID into provided
+// byte slice.
+func AppendNull(c []byte, id voxa.FieldID) []byte {
+	c, start := ReserveFrame(c)
+	return CloseFrame(AppendHeader(c, voxa.Null, id), start)
+}
+
+// IsNull returns true if provided frame content holds a Null Atom.
+func IsNull(content []byte) bool {
+	return len(content) > 0 && voxa.Atom(content[0]) == voxa.Null
+}
+
+// AppendFrame encodes provided value with provided codec as a frame marked
+// with provided FieldID, appending it into provided byte slice.
+func AppendFrame(codec voxa.Codec, v interface{}, id voxa.FieldID, c []byte) ([]byte, error) {
+	c, start := ReserveFrame(c)
+	encoded, err := codec.NativeToBinary(v, id, c)
+	if err != nil {
+		return c[:start], err
+	}
+	return CloseFrame(encoded, start), nil
+}
+
+// AppendValue encodes provided value as a frame marked with provided FieldID
+// using reflection, appending it into provided byte slice. Nil values are
+// encoded as a Null frame, and values which have no codec, such as channels,
+// are skipped.
+func AppendValue(v interface{}, id voxa.FieldID, c []byte) ([]byte, error) {
+	encoded, err := nativeItemToBinary(v, id, c)
+	if err == ErrSkipErr {
+		return c, nil
+	}
+	return encoded, err
+}
+
+// DecodeValue decodes provided frame into the value pointed to by target
+// using reflection, enforcing the voxa.DefaultLimits.
+func DecodeValue(frame []byte, target interface{}) error {
+	return DecodeValueLimits(frame, target, voxa.DefaultLimits())
+}
+
+// DecodeValueLimits decodes provided frame into the value pointed to by
+// target using reflection, enforcing provided limits, such as those received
+// by a voxa.LimitedUnmarshaler.
+func DecodeValueLimits(frame []byte, target interface{}, limits voxa.Limits) error {
+	return Engine{}.Unmarshal(frame, target, limits)
+}
+
+// EnterRecord returns provided limits for the fields of a record, failing
+// when the record exceeds the MaxDepth limit.
+func EnterRecord(limits voxa.Limits) (voxa.Limits, error) {
+	d := newDecodeState(nil, limits)
+	if err := d.enter(); err != nil {
+		return limits, err
+	}
+
+	limits.MaxDepth -= d.depth
+	return limits, nil
+}
+
+// EnterList returns provided limits for the items of a list with provided
+// items content and the number of items, failing when the list exceeds the
+// MaxDepth or MaxBlockCount limit.
+func EnterList(limits voxa.Limits, items []byte) (voxa.Limits, int, error) {
+	d := newDecodeState(nil, limits)
+	if err := d.enter(); err != nil {
+		return limits, 0, err
+	}
+
+	count := countBinaryItems(items)
+	if err := d.checkBlockCount(count); err != nil {
+		return limits, 0, err
+	}
+
+	limits.MaxDepth -= d.depth
+	return limits, count, nil
+}
+
+// FrameToInt64 decodes provided frame content holding any numeric Atom
+// into a int64.
+func FrameToInt64(content []byte) (int64, error) {
+	return FrameToInt(content, 64)
+}
+
+// FrameToUint64 decodes provided frame content holding any numeric Atom
+// into a uint64.
+func FrameToUint64(content []byte) (uint64, error) {
+	return FrameToUint(content, 64)
+}
+
+// FrameToFloat64 decodes provided frame content holding any numeric Atom
+// into a float64.
+func FrameToFloat64(content []byte) (float64, error) {
+	return FrameToFloat(content, 64)
+}
+
+// FrameToInt decodes provided frame content holding any numeric Atom into
+// a signed integer of provided bit size, where 0 is the size of an int,
+// failing when the value does not fit into it.
+func FrameToInt(content []byte, bitSize int) (int64, error) {
+	value, err := frameToNumber(content, sizedTypes[reflect.Int][bitSize])
+	if err != nil {
+		return 0, err
+	}
+	return value.Int(), nil
+}
+
+// FrameToUint decodes provided frame content holding any numeric Atom into
+// an unsigned integer of provided bit size, where 0 is the size of an uint,
+// failing when the value does not fit into it.
+func FrameToUint(content []byte, bitSize int) (uint64, error) {
+	value, err := frameToNumber(content, sizedTypes[reflect.Uint][bitSize])
+	if err != nil {
+		return 0, err
+	}
+	return value.Uint(), nil
+}
+
+// FrameToFloat decodes provided frame content holding any numeric Atom into
+// a float of provided bit size, failing when the value does not fit into
+// it.
+func FrameToFloat(content []byte, bitSize int) (float64, error) {
+	value, err := frameToNumber(content, sizedTypes[reflect.Float64][bitSize])
+	if err != nil {
+		return 0, err
+	}
+	return value.Float(), nil
+}
+
+// FrameToComplex128 decodes provided frame content holding a Complex64 or
+// Complex128 Atom into a complex128.
+func FrameToComplex128(content []byte) (complex128, error) {
+	value, _, err := complexCodec.BinaryToNative(content)
+	if err != nil {
+		return 0, err
+	}
+
+	if c, ok := value.(complex64); ok {
+		return complex128(c), nil
+	}
+	return value.(complex128), nil
+}
+
+// sizedTypes holds the numeric types of every bit size, keyed by the kind
+// of their int, uint or float64 family.
+var sizedTypes = map[reflect.Kind]map[int]reflect.Type{
+	reflect.Int: {
+		0:  kindTypes[reflect.Int],
+		8:  kindTypes[reflect.Int8],
+		16: kindTypes[reflect.Int16],
+		32: kindTypes[reflect.Int32],
+		64: kindTypes[reflect.Int64],
+	},
+	reflect.Uint: {
+		0:  kindTypes[reflect.Uint],
+		8:  kindTypes[reflect.Uint8],
+		16: kindTypes[reflect.Uint16],
+		32: kindTypes[reflect.Uint32],
+		64: kindTypes[reflect.Uint64],
+	},
+	reflect.Float64: {
+		32: kindTypes[reflect.Float32],
+		64: kindTypes[reflect.Float64],
+	},
+}
+
+// frameToNumber decodes provided frame content holding any numeric Atom
+// into a new value of provided numeric type, failing as setNumber does when
+// it does not fit.
+func frameToNumber(content []byte, t reflect.Type) (reflect.Value, error) {
+	if len(content) == 0 {
+		return reflect.Value{}, ErrInvalidDataSlice
+	}
+
+	if t == nil {
+		return reflect.Value{}, errors.New("bit size must be 0, 8, 16, 32 or 64")
+	}
+
+	atom := voxa.Atom(content[0])
+	codec, ok := codecForAtom(atom)
+	if !ok {
+		return reflect.Value{}, ErrUnknownType
+	}
+
+	value, _, err := codec.BinaryToNative(content)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	val := reflect.ValueOf(value)
+	if !isNumericKind(val.Kind()) {
+		return reflect.Value{}, fmt.Errorf("can not decode %s into a number", atom)
+	}
+
+	dest := reflect.New(t).Elem()
+	if err := setNumber(dest, val); err != nil {
+		return reflect.Value{}, err
+	}
+	return dest, nil
+}
+
+// FrameToBool decodes provided frame content holding a Boolean Atom.
+func FrameToBool(content []byte) (bool, error) {
+	value, _, err := boolCodec.BinaryToNative(content)
+	if err != nil {
+		return false, err
+	}
+	return value.(bool), nil
+}
+
+// FrameToText decodes provided frame content holding a Text Atom.
+func FrameToText(content []byte) (string, error) {
+	value, _, err := textCodec.BinaryToNative(content)
+	if err != nil {
+		return emptyString, err
+	}
+	return value.(string), nil
+}
+
+// FrameToTime decodes provided frame content holding a Time Atom.
+func FrameToTime(content []byte) (time.Time, error) {
+	value, _, err := timeCodec.BinaryToNative(content)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	tick, ok := value.(time.Time)
+	if !ok {
+		return time.Time{}, fmt.Errorf("can not decode %s into time.Time", voxa.Time)
+	}
+	return tick, nil
+}
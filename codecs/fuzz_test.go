@@ -0,0 +1,202 @@
+package codecs_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wirekit/voxa"
+	"github.com/wirekit/voxa/codecs"
+)
+
+type fuzzAddress struct {
+	Street string `id:"1"`
+	Zip    uint32 `id:"2"`
+}
+
+type fuzzRecord struct {
+	Age       int                    `id:"1"`
+	Name      string                 `id:"2"`
+	Score     float64                `id:"3"`
+	Active    bool                   `id:"4"`
+	Date      time.Time              `id:"5"`
+	Data      []byte                 `id:"6"`
+	Names     []string               `id:"7"`
+	Home      *fuzzAddress           `id:"8"`
+	Addresses []fuzzAddress          `id:"9"`
+	Matrix    [][]int64              `id:"10"`
+	Labels    map[string]int         `id:"11"`
+	Extra     map[interface{}]string `id:"12"`
+	Note      interface{}            `id:"13"`
+}
+
+func fuzzRecordSeed() fuzzRecord {
+	return fuzzRecord{
+		Age:       20,
+		Name:      "bob",
+		Score:     32.5,
+		Active:    true,
+		Date:      time.Date(2018, 4, 1, 10, 30, 0, 0, time.UTC),
+		Data:      []byte("raw"),
+		Names:     []string{"Rick Woss", "Ross Rics"},
+		Home:      &fuzzAddress{Street: "20. Classy Street", Zip: 2020},
+		Addresses: []fuzzAddress{{Street: "Lane", Zip: 1}},
+		Matrix:    [][]int64{{1, 2}, {-3}},
+		Labels:    map[string]int{"one": 1, "two": 2},
+		Extra:     map[interface{}]string{1: "one", "two": "two"},
+		Note:      []interface{}{"note", 1},
+	}
+}
+
+func FuzzRecordCodec_BinaryToNative(f *testing.F) {
+	record := fuzzRecordSeed()
+
+	var codec codecs.RecordCodec
+	encoded, err := codec.NativeToBinary(record, nil)
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(encoded)
+
+	described, err := codecs.RecordCodec{Described: true}.NativeToBinary(record, nil)
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(described)
+
+	f.Fuzz(func(t *testing.T, b []byte) {
+		var res fuzzRecord
+		codec.BinaryToNative(b, &res)
+
+		var fields map[string]interface{}
+		codec.BinaryToNative(b, &fields)
+
+		var values map[interface{}]interface{}
+		codec.BinaryToNative(b, &values)
+
+		var value interface{}
+		voxa.Unmarshal(b, &value)
+		voxa.DecodeAny(b)
+	})
+}
+
+func FuzzListCodec_BinaryToNative(f *testing.F) {
+	var codec codecs.ListCodec
+	for _, list := range []interface{}{
+		[]string{"Rick Woss", "Ross Rics"},
+		[][]int64{{1, 2}, {-3}},
+		[]float64{1.5, -2},
+		[3]uint16{1, 2, 65535},
+		[]fuzzAddress{{Street: "Lane", Zip: 1}},
+		[]interface{}{"note", 1, 2.5, true},
+		[]shape{circle{Radius: 1}, &square{Side: 2}, nil},
+	} {
+		encoded, err := codec.NativeToBinary(list, nil)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(encoded)
+	}
+
+	f.Fuzz(func(t *testing.T, b []byte) {
+		codec.BinaryToNative(b, &[]string{})
+		codec.BinaryToNative(b, &[][]int64{})
+		codec.BinaryToNative(b, &[]float64{})
+		codec.BinaryToNative(b, &[3]uint16{})
+		codec.BinaryToNative(b, &[]fuzzAddress{})
+		codec.BinaryToNative(b, &[]interface{}{})
+		codec.BinaryToNative(b, &[]shape{})
+		voxa.DecodeAny(b)
+	})
+}
+
+func FuzzMapCodec_BinaryToNative(f *testing.F) {
+	var codec codecs.MapCodec
+	for _, item := range []interface{}{
+		map[string]int{"one": 1, "two": 2},
+		map[interface{}]string{1: "one", "two": "two"},
+		map[int64][]string{1: {"one"}},
+	} {
+		encoded, err := codec.NativeToBinary(item, nil)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(encoded)
+	}
+
+	f.Fuzz(func(t *testing.T, b []byte) {
+		codec.BinaryToNative(b, &map[string]int{})
+		codec.BinaryToNative(b, &map[interface{}]interface{}{})
+		codec.BinaryToNative(b, &map[int64][]string{})
+	})
+}
+
+// fuzzScalarCodec seeds provided fuzz target with the encoding of every
+// provided value, checking every value the codec decodes is encoded back
+// into a frame it decodes again.
+func fuzzScalarCodec(f *testing.F, codec voxa.Codec, values ...interface{}) {
+	for _, value := range values {
+		encoded, err := codec.NativeToBinary(value, 1, nil)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(encoded)
+	}
+
+	f.Fuzz(func(t *testing.T, b []byte) {
+		decoded, id, err := codec.BinaryToNative(b)
+		if err != nil {
+			return
+		}
+
+		encoded, err := codec.NativeToBinary(decoded, id, nil)
+		if err != nil {
+			t.Fatalf("failed to encode decoded value %#v: %s", decoded, err)
+		}
+
+		if _, _, err := codec.BinaryToNative(encoded); err != nil {
+			t.Fatalf("failed to decode encoded value %#v: %s", decoded, err)
+		}
+	})
+}
+
+func FuzzIntCodec_BinaryToNative(f *testing.F) {
+	fuzzScalarCodec(f, codecs.IntCodec{}, 20, int8(-8), int16(16), int32(-32), int64(1<<40),
+		uint(20), uint8(8), uint16(16), uint32(32), uint64(1<<60))
+}
+
+func FuzzFloatCodec_BinaryToNative(f *testing.F) {
+	fuzzScalarCodec(f, codecs.FloatCodec{}, float32(32.5454), float64(-32.545))
+}
+
+func FuzzComplexCodec_BinaryToNative(f *testing.F) {
+	fuzzScalarCodec(f, codecs.ComplexCodec{}, complex64(1+2i), complex128(-3.5i))
+}
+
+func FuzzTextCodec_BinaryToNative(f *testing.F) {
+	fuzzScalarCodec(f, codecs.TextCodec{}, textValue, "")
+}
+
+func FuzzBytesCodec_BinaryToNative(f *testing.F) {
+	fuzzScalarCodec(f, codecs.BytesCodec{}, []byte("raw"), []byte{})
+}
+
+func FuzzBooleanCodec_BinaryToNative(f *testing.F) {
+	fuzzScalarCodec(f, codecs.BooleanCodec{}, true, false)
+}
+
+func FuzzTimeCodec_BinaryToNative(f *testing.F) {
+	fuzzScalarCodec(f, codecs.TimeCodec{}, time.Date(2018, 4, 1, 10, 30, 0, 0, time.UTC))
+}
+
+func FuzzHeaderCodec_BinaryToField(f *testing.F) {
+	var codec codecs.HeaderCodec
+	encoded, err := codec.FieldToBinary("Name", 2, voxa.Text, nil)
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(encoded)
+
+	f.Fuzz(func(t *testing.T, b []byte) {
+		codec.BinaryToField(b)
+	})
+}
@@ -13,7 +13,7 @@ var (
 
 // HeaderCodec implements the voxa.HeaderCodec providing
 // methods to turn giving field names and associated data into
-// a byte slice with format: `[FieldID][Atom][Name Bytes...]`
+// a byte slice with format: `[FieldID VarInt][Atom][Name Bytes...]`
 // and vice-versa.
 type HeaderCodec struct{}
 
@@ -22,13 +22,17 @@ func (HeaderCodec) BinaryToField(b []byte) (string, voxa.FieldID, voxa.Atom, err
 		return emptyString, 0, voxa.Invalid, errors.New("field byte slice must be longer than 2")
 	}
 
-	tp := voxa.Atom(b[0])
-	if tp > voxa.Record {
+	id, rest, err := readFieldID(b)
+	if err != nil || len(rest) < 2 {
+		return emptyString, 0, voxa.Invalid, errors.New("field byte slice must be longer than 2")
+	}
+
+	tp := voxa.Atom(rest[0])
+	if !knownAtom(tp) {
 		return emptyString, 0, voxa.Invalid, errors.New("field byte slice must have type bit within supported")
 	}
 
-	id := voxa.FieldID(b[1])
-	return string(b[2:]), id, tp, nil
+	return string(rest[1:]), id, tp, nil
 }
 
 func (HeaderCodec) FieldToBinary(name string, id voxa.FieldID, ty voxa.Atom, b []byte) ([]byte, error) {
@@ -37,10 +41,17 @@ func (HeaderCodec) FieldToBinary(name string, id voxa.FieldID, ty voxa.Atom, b [
 		return nil, errors.New("field name must not be an empty string")
 	}
 
-	if ty > voxa.Record {
+	if !knownAtom(ty) {
 		return nil, errors.New("field name type bit is not supported")
 	}
 
-	b = append(b, byte(id), byte(ty))
+	b = append(appendFieldID(b, id), byte(ty))
 	return append(b, name...), nil
 }
+
+// knownAtom returns true if provided Atom is one of the Atoms defined by
+// voxa. The Invalid Atom is allowed for fields whose Atom can only be
+// known from their value.
+func knownAtom(atom voxa.Atom) bool {
+	return atom <= voxa.Union
+}
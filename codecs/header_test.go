@@ -2,11 +2,57 @@ package codecs_test
 
 import (
 	"testing"
+
+	"github.com/influx6/faux/tests"
+	"github.com/wirekit/voxa"
+	"github.com/wirekit/voxa/codecs"
+)
+
+var (
+	goodEncodedHeader = append([]byte{12, byte(voxa.Text)}, "Username"...)
+	badEncodedHeader  = append([]byte{12, 250}, "Username"...)
 )
 
 func TestHeaderCodec_BinaryToField(t *testing.T) {
+	var codec codecs.HeaderCodec
+	name, id, atom, err := codec.BinaryToField(goodEncodedHeader)
+	if err != nil {
+		tests.FailedWithError(err, "expected no error with decoding")
+	}
+
+	if name != "Username" || id != 12 || atom != voxa.Text {
+		tests.Info("Received: %q %d %s", name, id, atom)
+		tests.Failed("Should have received expected field name, id and atom")
+	}
+	tests.Passed("Should have received expected field name, id and atom")
+
+	if _, _, _, err := codec.BinaryToField(badEncodedHeader); err == nil {
+		tests.Failed("expected an error with decoding unknown atom")
+	}
+	tests.Passed("Should have failed to decode unknown atom")
 }
 
 func TestHeaderCodec_FieldToBinary(t *testing.T) {
+	var codec codecs.HeaderCodec
+	encoded, err := codec.FieldToBinary(" Username ", 12, voxa.Text, nil)
+	if err != nil {
+		tests.FailedWithError(err, "expected no error with encoding")
+	}
+
+	if string(encoded) != string(goodEncodedHeader) {
+		tests.Info("Received: %#v", encoded)
+		tests.Info("Expected: %#v", goodEncodedHeader)
+		tests.Failed("Should have received expected encoded value")
+	}
+	tests.Passed("Should have received expected encoded value")
+
+	if _, err := codec.FieldToBinary("", 12, voxa.Text, nil); err == nil {
+		tests.Failed("expected an error with encoding empty name")
+	}
+	tests.Passed("Should have failed to encode empty name")
 
+	if _, err := codec.FieldToBinary("Time", 2, voxa.Time, nil); err != nil {
+		tests.FailedWithError(err, "expected no error with encoding time atom")
+	}
+	tests.Passed("Should have successfully encoded time atom")
 }
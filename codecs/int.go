@@ -15,19 +15,21 @@ var _ voxa.Codec = IntCodec{}
 type IntCodec struct{}
 
 func (IntCodec) BinaryToNative(b []byte) (interface{}, voxa.FieldID, error) {
-	if len(b) < 3 {
+	atom, id, val, err := ReadHeader(b)
+	if err != nil || len(val) == 0 {
 		return nil, 0, errors.New("byte slice must be of length 2")
 	}
 
-	id := voxa.FieldID(b[1])
-	val := b[2:]
-	switch voxa.Atom(b[0]) {
+	switch atom {
 	case voxa.Int:
 		dl, n := DecodeVarInt64(val)
 		if n == 0 {
 			return nil, id, ErrDecodeFailed
 		}
 
+		// ints were written as unsigned varints before the signed atoms
+		// existed, hence they decode as the unsigned value written, as a
+		// negative int can not be told apart from a large positive one.
 		return int(dl), id, nil
 	case voxa.UInt:
 		dl, n := DecodeVarInt64(val)
@@ -75,6 +77,27 @@ func (IntCodec) BinaryToNative(b []byte) (interface{}, voxa.FieldID, error) {
 		}
 
 		return int64(dl), id, nil
+	case voxa.SInt:
+		dl, n := DecodeVarInt64(val)
+		if n == 0 {
+			return nil, id, ErrDecodeFailed
+		}
+
+		return int(DecodeZigZag64(dl)), id, nil
+	case voxa.SInt32:
+		dl, n := DecodeVarInt64(val)
+		if n == 0 {
+			return nil, id, ErrDecodeFailed
+		}
+
+		return int32(DecodeZigZag64(dl)), id, nil
+	case voxa.SInt64:
+		dl, n := DecodeVarInt64(val)
+		if n == 0 {
+			return nil, id, ErrDecodeFailed
+		}
+
+		return DecodeZigZag64(dl), id, nil
 	case voxa.UInt64:
 		dl, n := DecodeVarInt64(val)
 		if n == 0 {
@@ -91,32 +114,32 @@ func (IntCodec) NativeToBinary(b interface{}, id voxa.FieldID, c []byte) ([]byte
 	switch val := b.(type) {
 	case uint:
 		if val < math.MaxUint32 {
-			return append(append(c, byte(voxa.UInt), byte(id)), EncodeVarInt32(uint32(val))...), nil
+			return append(AppendHeader(c, voxa.UInt, id), EncodeVarInt32(uint32(val))...), nil
 		} else {
-			return append(append(c, byte(voxa.UInt), byte(id)), EncodeVarInt64(uint64(val))...), nil
+			return append(AppendHeader(c, voxa.UInt, id), EncodeVarInt64(uint64(val))...), nil
 		}
 	case uint8:
-		return append(c, byte(voxa.UInt8), byte(id), val), nil
+		return append(AppendHeader(c, voxa.UInt8, id), val), nil
 	case uint16:
-		return append(append(c, byte(voxa.UInt16), byte(id)), EncodeUInt16(val)...), nil
+		return append(AppendHeader(c, voxa.UInt16, id), EncodeUInt16(val)...), nil
 	case uint32:
-		return append(append(c, byte(voxa.UInt32), byte(id)), EncodeVarInt32(val)...), nil
+		return append(AppendHeader(c, voxa.UInt32, id), EncodeVarInt32(val)...), nil
 	case uint64:
-		return append(append(c, byte(voxa.UInt64), byte(id)), EncodeVarInt64(val)...), nil
+		return append(AppendHeader(c, voxa.UInt64, id), EncodeVarInt64(val)...), nil
 	case int:
-		if val < math.MaxInt32 {
-			return append(append(c, byte(voxa.Int), byte(id)), EncodeVarInt32(uint32(val))...), nil
-		} else {
-			return append(append(c, byte(voxa.Int), byte(id)), EncodeVarInt64(uint64(val))...), nil
-		}
+		return append(AppendHeader(c, voxa.SInt, id), EncodeVarInt64(EncodeZigZag64(int64(val)))...), nil
 	case int8:
-		return append(c, byte(voxa.Int8), byte(id), uint8(val)), nil
+		return append(AppendHeader(c, voxa.Int8, id), uint8(val)), nil
 	case int16:
-		return append(append(c, byte(voxa.Int16), byte(id)), EncodeUInt16(uint16(val))...), nil
+		return append(AppendHeader(c, voxa.Int16, id), EncodeUInt16(uint16(val))...), nil
 	case int32:
-		return append(append(c, byte(voxa.Int32), byte(id)), EncodeVarInt32(uint32(val))...), nil
+		return append(AppendHeader(c, voxa.SInt32, id), EncodeVarInt64(EncodeZigZag64(int64(val)))...), nil
 	case int64:
-		return append(append(c, byte(voxa.Int64), byte(id)), EncodeVarInt64(uint64(val))...), nil
+		return append(AppendHeader(c, voxa.SInt64, id), EncodeVarInt64(EncodeZigZag64(val))...), nil
+	}
+
+	if val, ok := unnamed(b); ok {
+		return IntCodec{}.NativeToBinary(val, id, c)
 	}
 
 	return nil, errors.New("type is not a int/uint")
@@ -154,5 +177,9 @@ func (IntCodec) NativeToTextual(b interface{}, c []byte) ([]byte, error) {
 		return []byte(strconv.FormatInt(val, 10)), nil
 	}
 
+	if val, ok := unnamed(b); ok {
+		return IntCodec{}.NativeToTextual(val, c)
+	}
+
 	return nil, errors.New("type is not a int/uint")
 }
@@ -2,7 +2,9 @@ package codecs_test
 
 import (
 	"bytes"
+	"math"
 	"testing"
+	"time"
 
 	"github.com/influx6/faux/tests"
 	"github.com/wirekit/voxa"
@@ -10,11 +12,12 @@ import (
 )
 
 var (
-	intValue       = int32(26)
-	intTextual     = []byte("26")
-	intBytes       = codecs.EncodeVarInt32(uint32(intValue))
-	goodEncodedInt = append([]byte{byte(voxa.Int32), 1}, intBytes...)
-	badEncodedInt  = append([]byte{byte(voxa.Invalid), 1}, intBytes...)
+	intValue         = int32(26)
+	intTextual       = []byte("26")
+	intBytes         = codecs.EncodeVarInt64(codecs.EncodeZigZag64(int64(intValue)))
+	goodEncodedInt   = append([]byte{byte(voxa.SInt32), 1}, intBytes...)
+	badEncodedInt    = append([]byte{byte(voxa.Invalid), 1}, intBytes...)
+	legacyEncodedInt = append([]byte{byte(voxa.Int32), 1}, codecs.EncodeVarInt32(uint32(intValue))...)
 )
 
 func TestIntCodec_BinaryToNative(t *testing.T) {
@@ -39,6 +42,78 @@ func TestIntCodec_BinaryToNative(t *testing.T) {
 	}
 }
 
+func TestIntCodec_BinaryToNative_Legacy(t *testing.T) {
+	var codec codecs.IntCodec
+	decoded, _, err := codec.BinaryToNative(legacyEncodedInt)
+	if err != nil {
+		tests.FailedWithError(err, "expected no error with decoding")
+	}
+
+	if value, ok := decoded.(int32); !ok || value != intValue {
+		tests.Info("Received: %#v", decoded)
+		tests.Failed("Should have received expected decoded value from legacy encoding")
+	}
+	tests.Passed("Should have received expected decoded value from legacy encoding")
+}
+
+func TestIntCodec_BinaryToNative_LegacyRange(t *testing.T) {
+	// ints were written as unsigned varints before the signed atoms existed,
+	// hence they decode as the unsigned value written.
+	var codec codecs.IntCodec
+	for value, encoded := range map[int][]byte{
+		3000000000:         codecs.EncodeVarInt64(3000000000),
+		math.MaxUint32:     codecs.EncodeVarInt32(math.MaxUint32),
+		26:                 codecs.EncodeVarInt32(26),
+		math.MaxInt32:      codecs.EncodeVarInt64(math.MaxInt32),
+		math.MaxUint32 + 1: codecs.EncodeVarInt64(math.MaxUint32 + 1),
+	} {
+		decoded, _, err := codec.BinaryToNative(append([]byte{byte(voxa.Int), 1}, encoded...))
+		if err != nil {
+			tests.FailedWithError(err, "Should have successfully decoded legacy int")
+		}
+
+		if decoded != value {
+			tests.Info("Received: %#v", decoded)
+			tests.Info("Expected: %#v", value)
+			tests.Failed("Should have received expected value from legacy encoding")
+		}
+	}
+	tests.Passed("Should have received expected values from legacy encoding")
+}
+
+func TestIntCodec_NativeToBinary_Negative(t *testing.T) {
+	var codec codecs.IntCodec
+	for _, value := range []interface{}{int(-1), int32(-1), int64(-1), int(-2147483649), int32(math.MinInt32), int64(math.MinInt64)} {
+		encoded, err := codec.NativeToBinary(value, 1, []byte{})
+		if err != nil {
+			tests.FailedWithError(err, "Should have successfully encoded negative value")
+		}
+
+		decoded, _, err := codec.BinaryToNative(encoded)
+		if err != nil {
+			tests.FailedWithError(err, "Should have successfully decoded negative value")
+		}
+
+		if decoded != value {
+			tests.Info("Received: %#v", decoded)
+			tests.Info("Expected: %#v", value)
+			tests.Failed("Should have received expected negative value")
+		}
+	}
+	tests.Passed("Should have received expected negative values")
+
+	encoded, err := codec.NativeToBinary(int64(-1), 1, []byte{})
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded negative value")
+	}
+
+	if len(encoded) != 3 {
+		tests.Info("Received: %+q", encoded)
+		tests.Failed("Should have encoded -1 into a single byte")
+	}
+	tests.Passed("Should have encoded -1 into a single byte")
+}
+
 func TestIntCodec_NativeToBinary(t *testing.T) {
 	var codec codecs.IntCodec
 	encoded, err := codec.NativeToBinary(intValue, 1, []byte{})
@@ -91,3 +166,22 @@ func must(err error) bool {
 	}
 	return false
 }
+
+func TestIntCodec_NativeToBinary_Named(t *testing.T) {
+	var codec codecs.IntCodec
+	encoded, err := codec.NativeToBinary(time.Duration(20), 1, []byte{})
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded time.Duration")
+	}
+
+	decoded, _, err := codec.BinaryToNative(encoded)
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully decoded time.Duration")
+	}
+
+	if decoded != int64(20) {
+		tests.Info("Received: %#v", decoded)
+		tests.Failed("Should have decoded time.Duration as int64")
+	}
+	tests.Passed("Should have decoded time.Duration as int64")
+}
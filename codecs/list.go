@@ -2,11 +2,10 @@ package codecs
 
 import (
 	"errors"
+	"fmt"
 
 	"reflect"
 
-	"time"
-
 	"github.com/influx6/faux/pools/pbytes"
 	"github.com/wirekit/voxa"
 )
@@ -28,6 +27,14 @@ var (
 	// ErrInvalidFieldID is returned when the field id does not match expected.
 	ErrInvalidFieldID = errors.New("data id does not match expected field id")
 
+	// ErrMalformedFieldID is returned when the field id of a frame is not a
+	// valid varint FieldID.
+	ErrMalformedFieldID = errors.New("data field id is not a valid varint")
+
+	// ErrArrayLength is returned when the number of items of a list does not
+	// match the length of the array it is decoded into.
+	ErrArrayLength = errors.New("list length does not match array length")
+
 	// ErrSkipErr is returned when codec is not available for a giving type.
 	ErrSkipErr = errors.New("no codec available for type")
 )
@@ -39,25 +46,16 @@ var (
 type ListCodec struct{}
 
 func (lc ListCodec) BinaryToNative(b []byte, target interface{}) (interface{}, error) {
-	xl, read := DecodeVarInt64(b)
-	if xl == 0 {
-		return nil, ErrInvalidNoSize
-	}
+	d := newDecodeState(b, voxa.DefaultLimits())
 
-	headerFrame := b[:read+2]
-	dataFrame := b[read+2:]
-
-	if len(dataFrame) < int(xl-2) {
-		return nil, ErrInvalidDataSlice
+	frame, content, _, err := NextFrame(b)
+	if err != nil {
+		return nil, d.fail(b, err)
 	}
 
-	//itemCount := countBinaryItems(dataFrame)
-	//fmt.Printf("BinaryToNative:Items %d\n", itemCount)
-
-	header := headerFrame[read:]
-	htype := voxa.Atom(header[0])
-	if htype != voxa.List {
-		return nil, ErrNotList
+	atom := voxa.Atom(content[0])
+	if atom != voxa.List && atom != voxa.Packed {
+		return nil, &voxa.DecodeError{Expected: voxa.List, Actual: atom, Err: ErrNotList}
 	}
 
 	var itemVal reflect.Value
@@ -67,126 +65,174 @@ func (lc ListCodec) BinaryToNative(b []byte, target interface{}) (interface{}, e
 		itemVal = reflect.ValueOf(target)
 	}
 
-	item := itemVal.Type()
-	if item.Kind() == reflect.Ptr {
-		item = item.Elem()
+	if itemVal.Kind() == reflect.Ptr {
+		itemVal = itemVal.Elem()
 	}
 
-	if item.Kind() != reflect.Slice {
-		return nil, errors.New("only array and slice types acceptable")
-	}
+	// arrays are decoded in place, hence must be settable.
+	if itemVal.Kind() == reflect.Array {
+		if !itemVal.CanSet() {
+			return nil, ErrValueUnsettable
+		}
 
-	typeKind := item.Elem()
+		if atom == voxa.Packed {
+			err = lc.decodePacked(d, frame, content, itemVal)
+		} else {
+			err = lc.decodeArray(d, content, itemVal)
+		}
 
-	var err error
-	for len(dataFrame) > 0 {
-		subXL, subRead := DecodeVarInt64(dataFrame)
-		if subXL == 0 {
-			return nil, ErrInvalidNoSize
+		if err != nil {
+			return nil, d.fail(frame, err)
 		}
+		return itemVal.Interface(), nil
+	}
+
+	if itemVal.Kind() != reflect.Slice {
+		return nil, errors.New("only array and slice types acceptable")
+	}
 
-		totalFrame := subRead + int(subXL)
-		frame := dataFrame[0:totalFrame]
-		subDataFrame := frame[subRead:]
-
-		var newValue reflect.Value
-		// we are dealing with a sublist, then we must backtrack
-		// and ensure to have full header and body.
-		atom := voxa.Atom(subDataFrame[0])
-		switch atom {
-		case voxa.List:
-			itemCount := countBinaryItems(subDataFrame[2:])
-			newValue = reflect.MakeSlice(typeKind, 0, int(itemCount))
-			subDataFrame = frame
-		case voxa.Record:
-			newValue = reflect.New(typeKind)
-			subDataFrame = frame
-		default:
-			newValue = reflect.New(typeKind).Elem()
+	// packed values are appended to the slice as list elements are.
+	if atom == voxa.Packed {
+		packed := reflect.New(itemVal.Type()).Elem()
+		if err := lc.decodePacked(d, frame, content, packed); err != nil {
+			return nil, d.fail(frame, err)
 		}
+		return reflect.AppendSlice(itemVal, packed).Interface(), nil
+	}
+
+	list, err := lc.appendList(d, content, itemVal)
+	if err != nil {
+		return nil, d.fail(frame, err)
+	}
+
+	return list.Interface(), nil
+}
+
+// appendList decodes the frames within provided list content as elements
+// appended to provided slice value, returning the new slice value.
+func (lc ListCodec) appendList(d *decodeState, content []byte, list reflect.Value) (reflect.Value, error) {
+	if err := d.enter(); err != nil {
+		return list, err
+	}
+	defer d.leave()
 
-		newValue, err = lc.binaryToNativeItem(atom, subDataFrame, newValue)
-		if err != nil && err != ErrSkipErr {
-			return nil, err
+	_, _, dataFrame, err := ReadHeader(content)
+	if err != nil {
+		return list, err
+	}
+
+	itemCount := countBinaryItems(dataFrame)
+	if err := d.checkCount(list.Len()+itemCount, list.Type().Elem()); err != nil {
+		return list, err
+	}
+
+	// grow slice to fit all items once, instead of on every append.
+	if list.Cap()-list.Len() < itemCount {
+		grown := reflect.MakeSlice(list.Type(), list.Len(), list.Len()+itemCount)
+		reflect.Copy(grown, list)
+		list = grown
+	}
+
+	elemType := list.Type().Elem()
+	for len(dataFrame) > 0 {
+		frame, subContent, rest, err := NextFrame(dataFrame)
+		if err != nil {
+			return list, d.fail(dataFrame, err)
 		}
 
-		if typeKind.Kind() != reflect.Ptr && newValue.Kind() == reflect.Ptr {
-			newValue = newValue.Elem()
+		elem := reflect.New(elemType).Elem()
+		if err := d.decodeValue(frame, subContent, elem); err != nil {
+			return list, withPath(err, fmt.Sprintf("[%d]", list.Len()))
 		}
 
-		itemVal = reflect.Append(itemVal, newValue)
+		list = reflect.Append(list, elem)
 
 		// Reduce current length of slice.
-		dataFrame = dataFrame[totalFrame:]
+		dataFrame = rest
 	}
 
-	return itemVal.Interface(), nil
+	return list, nil
 }
 
-// BinaryToNativeItem attempts to convert a singular item within provided data within the byte slice
-// into the provided reflect.Value, it ensures the internal data field ID attached in the encoded
-// data match the provided. It returns an error if the id does not match, or if the value could not
-// be decoded safely, more so, the value must be settable.
-func (lc ListCodec) binaryToNativeItem(atom voxa.Atom, data []byte, dest reflect.Value) (reflect.Value, error) {
-	if atom < voxa.List && !dest.CanSet() {
-		return dest, ErrValueUnsettable
+// decodeArray decodes the frames within provided list content into the
+// elements of provided array value in place, failing when their number does
+// not match the length of the array.
+func (lc ListCodec) decodeArray(d *decodeState, content []byte, array reflect.Value) error {
+	if err := d.enter(); err != nil {
+		return err
 	}
+	defer d.leave()
 
-	switch atom {
-	case voxa.Record:
-		err := recordCodec.BinaryToNative(data, dest)
-		if err != nil {
-			return dest, err
-		}
-	case voxa.List:
-		value, err := listCodec.BinaryToNative(data, dest)
-		if err != nil {
-			return dest, err
-		}
+	_, _, dataFrame, err := ReadHeader(content)
+	if err != nil {
+		return err
+	}
 
-		dest = reflect.ValueOf(value)
-	case voxa.Time:
-		value, _, err := timeCodec.BinaryToNative(data)
-		if err != nil {
-			return dest, err
-		}
-		dest.Set(reflect.ValueOf(value))
-	case voxa.Text:
-		value, _, err := textCodec.BinaryToNative(data)
-		if err != nil {
-			return dest, err
-		}
-		dest.Set(reflect.ValueOf(value))
-	case voxa.Bytes:
-		value, _, err := bytesCodec.BinaryToNative(data)
-		if err != nil {
-			return dest, err
-		}
-		dest.Set(reflect.ValueOf(value))
-	case voxa.Boolean:
-		value, _, err := boolCodec.BinaryToNative(data)
-		if err != nil {
-			return dest, err
-		}
-		dest.Set(reflect.ValueOf(value))
-	case voxa.Float64, voxa.Float32:
-		value, _, err := floatCodec.BinaryToNative(data)
+	// every frame is counted when the list is well formed.
+	if count := countBinaryItems(dataFrame); count != array.Len() {
+		return fmt.Errorf("can not decode %d items into %q: %w", count, array.Type(), ErrArrayLength)
+	}
+
+	for i := 0; len(dataFrame) > 0; i++ {
+		frame, subContent, rest, err := NextFrame(dataFrame)
 		if err != nil {
-			return dest, err
+			return d.fail(dataFrame, err)
 		}
-		dest.Set(reflect.ValueOf(value))
-	case voxa.Int, voxa.UInt, voxa.UInt8, voxa.UInt16, voxa.UInt32, voxa.UInt64,
-		voxa.Int8, voxa.Int16, voxa.Int32, voxa.Int64:
 
-		value, _, err := intCodec.BinaryToNative(data)
-		if err != nil {
-			return dest, err
+		if err := d.decodeValue(frame, subContent, array.Index(i)); err != nil {
+			return withPath(err, fmt.Sprintf("[%d]", i))
 		}
 
-		dest.Set(reflect.ValueOf(value))
+		dataFrame = rest
 	}
 
-	return dest, nil
+	return nil
+}
+
+// decodeByteArray copies provided Bytes frame content into provided byte
+// array value, failing when their lengths do not match.
+func decodeByteArray(content []byte, array reflect.Value) error {
+	_, _, data, err := ReadHeader(content)
+	if err != nil {
+		return err
+	}
+
+	if len(data) != array.Len() {
+		return fmt.Errorf("can not decode %d bytes into %q: %w", len(data), array.Type(), ErrArrayLength)
+	}
+
+	for i, b := range data {
+		array.Index(i).SetUint(uint64(b))
+	}
+	return nil
+}
+
+// decodeByteSlice copies provided Bytes frame content into a new slice set
+// into provided byte slice value, hence it does not share the decoded bytes.
+func decodeByteSlice(content []byte, slice reflect.Value) error {
+	_, _, data, err := ReadHeader(content)
+	if err != nil {
+		return err
+	}
+
+	slice.Set(reflect.MakeSlice(slice.Type(), len(data), len(data)))
+	copy(slice.Bytes(), data)
+	return nil
+}
+
+// encodeBytes encodes provided byte array or slice value as a Bytes frame,
+// or a Null frame when it is a nil slice.
+func encodeBytes(bytes reflect.Value, id voxa.FieldID, c []byte) ([]byte, error) {
+	if bytes.Kind() == reflect.Slice && bytes.IsNil() {
+		return AppendNull(c, id), nil
+	}
+
+	c, start := ReserveFrame(c)
+	c = AppendHeader(c, voxa.Bytes, id)
+	for i := 0; i < bytes.Len(); i++ {
+		c = append(c, byte(bytes.Index(i).Uint()))
+	}
+	return CloseFrame(c, start), nil
 }
 
 func (lc ListCodec) NativeToBinary(b interface{}, c []byte) ([]byte, error) {
@@ -203,141 +249,83 @@ func (lc ListCodec) NativeToBinaryFrom(b interface{}, id voxa.FieldID, c []byte)
 		return nil, errors.New("only array and slice types acceptable")
 	}
 
-	totalElements := item.Len()
-
-	itemType := item.Type()
-	itemTypeElem := itemType.Elem()
-	itemTypeSizeUPtr := int(itemTypeElem.Size())
-	itemTypeConservativeSize := itemTypeSizeUPtr * totalElements * 128
+	itemTypeSizeUPtr := int(item.Type().Elem().Size())
+	itemTypeConservativeSize := itemTypeSizeUPtr * item.Len() * 128
 
 	buffer := slicePool.Get(itemTypeConservativeSize)
 	defer buffer.Discard()
 
-	var err error
-	base := buffer.Data[:0]
-
-	for i := 0; i < totalElements; i++ {
-		indexValue := item.Index(i)
-		base, err = nativeItemToBinary(indexValue.Interface(), voxa.FieldID(i), base)
-		if err != nil {
-			return c, err
-		}
+	encoded, err := lc.encodeList(item, id, buffer.Data[:0])
+	if err != nil {
+		return c, encodeError(err, "", item.Type())
 	}
 
-	// payload is the total length of encoded contents + length of items count slice + 2 bits for flags.
-	c = append(c, EncodeVarInt64(uint64(len(base)+2))...)
-	c = append(c, byte(voxa.List), byte(id))
-	c = append(c, base...)
-	return c, nil
+	return append(c, encoded...), nil
 }
 
-// NativeItemToBinary attempts to convert a non-slice/non-array type but basic data types, structs
-// which are elements of a slice into it's basic type as part of a list identified with a Field id.
-func nativeItemToBinary(b interface{}, id voxa.FieldID, c []byte) ([]byte, error) {
-	itemType := reflect.TypeOf(b)
-	if itemType.Kind() == reflect.Ptr {
-		itemType = itemType.Elem()
+// encodeList encodes provided slice or array value as a list frame, where
+// each element is marked with it's index as FieldID. Slices and arrays of
+// numbers and booleans are encoded as a Packed frame instead.
+func (lc ListCodec) encodeList(item reflect.Value, id voxa.FieldID, c []byte) ([]byte, error) {
+	if item.Kind() == reflect.Slice && item.IsNil() {
+		return AppendNull(c, id), nil
 	}
 
-	switch itemType.Kind() {
-	case reflect.Bool:
-		encoded, err := boolCodec.NativeToBinary(b, id, nil)
-		if err != nil {
-			return nil, err
-		}
-
-		// Calculate total length of encoded value + length of type flag (1).
-		return append(append(c, EncodeVarInt64(uint64(len(encoded)))...), encoded...), nil
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Int,
-		reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		// We can guarantee we only need 3 bits for storing a bool
-		// in voxa format.
-		content := slicePool.Get(32)
-		defer content.Discard()
-
-		encoded, err := intCodec.NativeToBinary(b, id, content.Data[:0])
-		if err != nil {
-			return nil, err
-		}
-
-		// Calculate total length of encoded value + length of type flag (1).
-		return append(append(c, EncodeVarInt64(uint64(len(encoded)))...), encoded...), nil
-	case reflect.String:
-		textLength := len(b.(string)) + 5
-		content := slicePool.Get(textLength)
-		defer content.Discard()
+	if elem, ok := packedAtom(item.Type().Elem()); ok {
+		return encodePacked(item, elem, id, c), nil
+	}
 
-		encoded, err := textCodec.NativeToBinary(b, id, content.Data[:0])
-		if err != nil {
-			return nil, err
-		}
+	totalElements := item.Len()
 
-		// Calculate total length of encoded value + length of type flag (1).
-		return append(append(c, EncodeVarInt64(uint64(len(encoded)))...), encoded...), nil
-	case reflect.Float32, reflect.Float64:
-		// We can guarantee there will enough space to cover
-		// this write has the slicePool has a 32bit start length.
-		content := slicePool.Get(32)
-		defer content.Discard()
+	c, start := ReserveFrame(c)
+	c = AppendHeader(c, voxa.List, id)
 
-		encoded, err := floatCodec.NativeToBinary(b, id, content.Data[:0])
-		if err != nil {
-			return nil, err
+	if totalElements > 0 {
+		encode := encoderFor(item.Type().Elem())
+		if encode == nil {
+			return c[:start], ErrSkipErr
 		}
 
-		// Calculate total length of encoded value + length of type flag (1).
-		return append(append(c, EncodeVarInt64(uint64(len(encoded)))...), encoded...), nil
-	case reflect.Struct, reflect.Map:
-		// We can guarantee there will enough space to cover
-		// this write has the slicePool has a 32bit start length.
-		content := slicePool.Get(1024)
-		defer content.Discard()
-
-		if _, ok := b.(time.Time); ok {
-			encoded, err := timeCodec.NativeToBinary(b, id, content.Data[:0])
+		var err error
+		for i := 0; i < totalElements; i++ {
+			c, err = encode(item.Index(i), voxa.FieldID(i), c)
 			if err != nil {
-				return nil, err
+				return c[:start], encodeError(err, fmt.Sprintf("[%d]", i), item.Type().Elem())
 			}
-
-			// Calculate total length of encoded value + length of type flag (1).
-			return append(append(c, EncodeVarInt64(uint64(len(encoded)))...), encoded...), nil
-		}
-
-		encoded, err := recordCodec.NativeToBinaryFrom(b, id, content.Data[:0])
-		if err != nil {
-			return nil, err
 		}
+	}
 
-		// Calculate total length of encoded value + length of type flag (1).
-		return append(c, encoded...), nil
-	case reflect.Slice:
-		// We can guarantee there will enough space to cover
-		// this write has the slicePool has a 32bit start length.
-		content := slicePool.Get(1024)
-		defer content.Discard()
+	return CloseFrame(c, start), nil
+}
 
-		encoded, err := listCodec.NativeToBinaryFrom(b, id, content.Data[:0])
-		if err != nil {
-			return nil, err
-		}
+// NativeItemToBinary attempts to convert a non-slice/non-array type but basic data types, structs
+// which are elements of a slice into it's basic type as part of a list identified with a Field id.
+func nativeItemToBinary(b interface{}, id voxa.FieldID, c []byte) ([]byte, error) {
+	item := reflect.ValueOf(b)
+	if !item.IsValid() {
+		return AppendNull(c, id), nil
+	}
 
-		// Calculate total length of encoded value + length of type flag (1).
-		return append(c, encoded...), nil
+	encode := encoderFor(item.Type())
+	if encode == nil {
+		return c, ErrSkipErr
 	}
 
-	return nil, ErrSkipErr
+	return encode(item, id, c)
 }
 
+// countBinaryItems returns the number of complete frames within provided
+// byte slice, stopping at the first malformed frame, such as one too short
+// to hold an Atom and FieldID.
 func countBinaryItems(b []byte) int {
 	var seen int
 	for len(b) > 0 {
 		subarea, read := DecodeVarInt64(b)
-		if read == 0 {
+		if read == 0 || subarea < 2 || subarea > uint64(len(b)-read) {
 			return seen
 		}
 
-		frame := int(subarea) + read
-		b = b[frame:]
+		b = b[read+int(subarea):]
 		seen++
 	}
 	return seen
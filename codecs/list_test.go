@@ -1,6 +1,10 @@
 package codecs_test
 
 import (
+	"bytes"
+	"errors"
+	"math"
+	"runtime"
 	"testing"
 
 	"reflect"
@@ -8,6 +12,7 @@ import (
 	"encoding/json"
 
 	"github.com/influx6/faux/tests"
+	"github.com/wirekit/voxa"
 	"github.com/wirekit/voxa/codecs"
 )
 
@@ -509,3 +514,383 @@ func TestListCodec_NativeToBinary_NestedList(t *testing.T) {
 	}
 	tests.Passed("Should have matching elements between input and output")
 }
+
+func TestListCodec_NativeToBinary_Marshaler(t *testing.T) {
+	contents := []temperature{{celsius: 21.5}, {celsius: -3.25}, {celsius: 40}}
+
+	var codec codecs.ListCodec
+	encoded, err := codec.NativeToBinary(contents, []byte{})
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded value with list codec")
+	}
+	tests.Passed("Should have successfully encoded value with list codec")
+
+	response, err := codec.BinaryToNative(encoded, reflect.ValueOf([]temperature{}))
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully decoded value with list codec")
+	}
+	tests.Passed("Should have successfully decoded value with list codec")
+
+	if !reflect.DeepEqual(response, contents) {
+		tests.Info("Received: %#v", response)
+		tests.Info("Expected: %#v", contents)
+		tests.Failed("Should have matching elements between input and output")
+	}
+	tests.Passed("Should have matching elements between input and output")
+}
+
+func TestListCodec_BinaryToNative_Truncated(t *testing.T) {
+	contents := []string{"Rick Woss", "Ross Rics"}
+
+	var codec codecs.ListCodec
+	encoded, err := codec.NativeToBinary(contents, []byte{})
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded value with list codec")
+	}
+
+	// claim a longer first item than the list holds.
+	encoded[3] = 0x7f
+
+	if _, err := codec.BinaryToNative(encoded, &[]string{}); err == nil {
+		tests.Failed("Should have failed to decode list with truncated item")
+	}
+	tests.Passed("Should have failed to decode list with truncated item")
+}
+
+func TestListCodec_BinaryToNative_EmptyFrames(t *testing.T) {
+	// a list of zero length frames, which are too short to be decoded.
+	encoded, start := codecs.ReserveFrame(nil)
+	encoded = codecs.AppendHeader(encoded, voxa.List, 0)
+	encoded = codecs.CloseFrame(append(encoded, make([]byte, 200000)...), start)
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+
+	var codec codecs.ListCodec
+	if _, err := codec.BinaryToNative(encoded, &[]struct{ Blob [4096]byte }{}); err == nil {
+		tests.Failed("Should have failed to decode list of empty frames")
+	}
+	tests.Passed("Should have failed to decode list of empty frames")
+
+	runtime.ReadMemStats(&after)
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 1<<20 {
+		tests.Info("Allocated: %d bytes", allocated)
+		tests.Failed("Should have allocated elements for the complete frames of the list only")
+	}
+	tests.Passed("Should have allocated elements for the complete frames of the list only")
+}
+
+func TestListCodec_BinaryToNative_Array(t *testing.T) {
+	position := [3]float64{1.5, -2, 300}
+
+	var codec codecs.ListCodec
+	encoded, err := codec.NativeToBinary(position, []byte{})
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded array with list codec")
+	}
+	tests.Passed("Should have successfully encoded array with list codec")
+
+	var res [3]float64
+	if _, err := codec.BinaryToNative(encoded, &res); err != nil {
+		tests.FailedWithError(err, "Should have successfully decoded array with list codec")
+	}
+	tests.Passed("Should have successfully decoded array with list codec")
+
+	if res != position {
+		tests.Info("Received: %#v", res)
+		tests.Info("Expected: %#v", position)
+		tests.Failed("Should have matching arrays between input and res")
+	}
+	tests.Passed("Should have matching arrays between input and res")
+
+	if _, err := codec.BinaryToNative(encoded, &[2]float64{}); !errors.Is(err, codecs.ErrArrayLength) {
+		tests.Info("Received: %#v", err)
+		tests.Failed("Should have failed to decode list into shorter array")
+	}
+	tests.Passed("Should have failed to decode list into shorter array")
+}
+
+func TestListCodec_ByteArray(t *testing.T) {
+	type hashed struct {
+		Hash   [32]byte      `id:"1"`
+		Hashes [][4]byte     `id:"2"`
+		Points [2][2]float32 `id:"3"`
+	}
+
+	record := hashed{Hashes: [][4]byte{{1, 2, 3, 4}}, Points: [2][2]float32{{1, 2}, {3, 4}}}
+	for i := range record.Hash {
+		record.Hash[i] = byte(i * 8)
+	}
+
+	encoded, err := voxa.Marshal(record)
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded arrays")
+	}
+	tests.Passed("Should have successfully encoded arrays")
+
+	// the hash is the first field: [Length][Record][FieldID][Length][Bytes][FieldID][32 bytes].
+	if voxa.Atom(encoded[4]) != voxa.Bytes || !bytes.Equal(encoded[6:38], record.Hash[:]) {
+		tests.Info("Received: %#v", encoded)
+		tests.Failed("Should have encoded byte array as Bytes frame")
+	}
+	tests.Passed("Should have encoded byte array as Bytes frame")
+
+	var res hashed
+	if err := voxa.Unmarshal(encoded, &res); err != nil {
+		tests.FailedWithError(err, "Should have successfully decoded arrays")
+	}
+	tests.Passed("Should have successfully decoded arrays")
+
+	if !reflect.DeepEqual(res, record) {
+		tests.Info("Received: %#v", res)
+		tests.Info("Expected: %#v", record)
+		tests.Failed("Should have matching arrays between input and res")
+	}
+	tests.Passed("Should have matching arrays between input and res")
+
+	var short struct {
+		Hash [16]byte `id:"1"`
+	}
+
+	if err := voxa.Unmarshal(encoded, &short); !errors.Is(err, codecs.ErrArrayLength) {
+		tests.Info("Received: %#v", err)
+		tests.Failed("Should have failed to decode bytes into shorter array")
+	}
+	tests.Passed("Should have failed to decode bytes into shorter array")
+}
+
+func TestListCodec_ByteSlice(t *testing.T) {
+	type raw []byte
+	type blob struct {
+		Data  []byte `id:"1"`
+		Named raw    `id:"2"`
+	}
+
+	record := blob{Data: []byte("voxa"), Named: raw("raw")}
+
+	encoded, err := voxa.Marshal(record)
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded byte slices")
+	}
+	tests.Passed("Should have successfully encoded byte slices")
+
+	// the data is the first field: [Length][Record][FieldID][Length][Bytes][FieldID][4 bytes].
+	if voxa.Atom(encoded[4]) != voxa.Bytes || !bytes.Equal(encoded[6:10], record.Data) {
+		tests.Info("Received: %#v", encoded)
+		tests.Failed("Should have encoded byte slice as Bytes frame as byte arrays are")
+	}
+	tests.Passed("Should have encoded byte slice as Bytes frame as byte arrays are")
+
+	var res blob
+	if err := voxa.Unmarshal(encoded, &res); err != nil {
+		tests.FailedWithError(err, "Should have successfully decoded byte slices")
+	}
+	tests.Passed("Should have successfully decoded byte slices")
+
+	for i := range encoded {
+		encoded[i] = 0
+	}
+
+	if !reflect.DeepEqual(res, record) {
+		tests.Info("Received: %#v", res)
+		tests.Info("Expected: %#v", record)
+		tests.Failed("Should have copied decoded bytes rather than sharing the input")
+	}
+	tests.Passed("Should have copied decoded bytes rather than sharing the input")
+
+	// byte slices were written as packed values before.
+	packed, err := voxa.Marshal([]uint16{1, 2})
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded packed values")
+	}
+
+	var data []byte
+	if err := voxa.Unmarshal(packed, &data); err != nil || !bytes.Equal(data, []byte{1, 2}) {
+		tests.Info("Received: %#v and %+q", data, err)
+		tests.Failed("Should have decoded packed values into byte slice")
+	}
+	tests.Passed("Should have decoded packed values into byte slice")
+}
+
+func TestListCodec_Packed(t *testing.T) {
+	contents := []int64{1, -2, 300}
+
+	var codec codecs.ListCodec
+	encoded, err := codec.NativeToBinary(contents, []byte{})
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded value with list codec")
+	}
+	tests.Passed("Should have successfully encoded value with list codec")
+
+	// [Length][Packed][FieldID][Element Atom][Count][ZigZag VarInts...]
+	expected := []byte{8, byte(voxa.Packed), 0, byte(voxa.SInt64), 3, 2, 3, 0xd8, 0x04}
+	if !bytes.Equal(encoded, expected) {
+		tests.Info("Received: %#v", encoded)
+		tests.Info("Expected: %#v", expected)
+		tests.Failed("Should have encoded slice as Packed frame")
+	}
+	tests.Passed("Should have encoded slice as Packed frame")
+
+	var widened []float64
+	if err := voxa.Unmarshal(encoded, &widened); err != nil {
+		tests.FailedWithError(err, "Should have successfully decoded packed values into floats")
+	}
+
+	if !reflect.DeepEqual(widened, []float64{1, -2, 300}) {
+		tests.Info("Received: %#v", widened)
+		tests.Failed("Should have converted packed values into floats")
+	}
+	tests.Passed("Should have converted packed values into floats")
+
+	var value interface{}
+	if err := voxa.Unmarshal(encoded, &value); err != nil {
+		tests.FailedWithError(err, "Should have successfully decoded packed values into interface")
+	}
+
+	if !reflect.DeepEqual(value, []interface{}{int64(1), int64(-2), int64(300)}) {
+		tests.Info("Received: %#v", value)
+		tests.Failed("Should have decoded packed values as their scalar types")
+	}
+	tests.Passed("Should have decoded packed values as their scalar types")
+
+	var text []string
+	if err := voxa.Unmarshal(encoded, &text); !errors.Is(err, codecs.ErrUnexpectedAtom) {
+		tests.Info("Received: %#v", err)
+		tests.Failed("Should have failed to decode packed numbers into strings")
+	}
+	tests.Passed("Should have failed to decode packed numbers into strings")
+
+	// claim more values than the frame holds.
+	encoded[4] = 9
+
+	var res []int64
+	if err := voxa.Unmarshal(encoded, &res); !errors.Is(err, codecs.ErrMalformedPacked) {
+		tests.Info("Received: %#v", err)
+		tests.Failed("Should have failed to decode packed values not matching their count")
+	}
+	tests.Passed("Should have failed to decode packed values not matching their count")
+}
+
+func TestListCodec_Packed_Kinds(t *testing.T) {
+	type metrics struct {
+		Flags   []bool    `id:"1"`
+		Small   []int8    `id:"2"`
+		Ports   []uint16  `id:"3"`
+		Counts  []uint32  `id:"4"`
+		Deltas  []int     `id:"5"`
+		Ratios  []float32 `id:"6"`
+		Samples []float64 `id:"7"`
+		Empty   []int64   `id:"8"`
+		Window  [2]int16  `id:"9"`
+		Raw     []byte    `id:"10"`
+	}
+
+	record := metrics{
+		Flags:   []bool{true, false, true},
+		Small:   []int8{-128, 0, 127},
+		Ports:   []uint16{80, 65535},
+		Counts:  []uint32{0, 4294967295},
+		Deltas:  []int{-1, 1 << 40},
+		Ratios:  []float32{0.5, -1.25},
+		Samples: []float64{3.14159, -0.001, 1e300},
+		Empty:   []int64{},
+		Window:  [2]int16{-32768, 32767},
+		Raw:     []byte("raw"),
+	}
+
+	encoded, err := voxa.Marshal(record)
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded packed slices")
+	}
+	tests.Passed("Should have successfully encoded packed slices")
+
+	var res metrics
+	if err := voxa.Unmarshal(encoded, &res); err != nil {
+		tests.FailedWithError(err, "Should have successfully decoded packed slices")
+	}
+	tests.Passed("Should have successfully decoded packed slices")
+
+	if !reflect.DeepEqual(res, record) {
+		tests.Info("Received: %#v", res)
+		tests.Info("Expected: %#v", record)
+		tests.Failed("Should have matching slices between input and res")
+	}
+	tests.Passed("Should have matching slices between input and res")
+}
+
+func TestListCodec_Packed_List(t *testing.T) {
+	// slices of numbers were encoded as a frame per element before they
+	// were packed.
+	c, start := codecs.ReserveFrame(nil)
+	c = codecs.AppendHeader(c, voxa.List, 0)
+	for i, value := range []int64{1, -2, 300} {
+		var err error
+		if c, err = codecs.AppendFrame(codecs.IntCodec{}, value, voxa.FieldID(i), c); err != nil {
+			tests.FailedWithError(err, "Should have successfully encoded list item")
+		}
+	}
+	encoded := codecs.CloseFrame(c, start)
+
+	var res []int64
+	if err := voxa.Unmarshal(encoded, &res); err != nil {
+		tests.FailedWithError(err, "Should have successfully decoded list of numbers")
+	}
+	tests.Passed("Should have successfully decoded list of numbers")
+
+	if !reflect.DeepEqual(res, []int64{1, -2, 300}) {
+		tests.Info("Received: %#v", res)
+		tests.Failed("Should have matching elements between input and res")
+	}
+	tests.Passed("Should have matching elements between input and res")
+}
+
+func TestListCodec_Packed_Overflow(t *testing.T) {
+	for _, item := range []struct {
+		list interface{}
+		dest interface{}
+	}{
+		{list: []int64{1000, math.MaxInt64}, dest: &[]int8{}},
+		{list: []int64{1, -1}, dest: &[]uint{}},
+		{list: []uint64{math.MaxUint64}, dest: &[]int64{}},
+		{list: []float64{1.5}, dest: &[]int{}},
+		{list: []float64{math.MaxFloat64}, dest: &[1]float32{}},
+	} {
+		encoded, err := voxa.Marshal(item.list)
+		if err != nil {
+			tests.FailedWithError(err, "Should have successfully encoded list")
+		}
+
+		err = voxa.Unmarshal(encoded, item.dest)
+
+		var decodeErr *voxa.DecodeError
+		if !errors.As(err, &decodeErr) || !errors.Is(err, codecs.ErrUnexpectedAtom) {
+			tests.Info("Received: %#v and %+q", item.dest, err)
+			tests.Failed("Should have failed to decode %#v into narrower elements", item.list)
+		}
+	}
+	tests.Passed("Should have failed to decode values which do not fit into elements")
+
+	encoded, err := voxa.Marshal([]int64{1000, math.MaxInt64})
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded list")
+	}
+
+	var decodeErr *voxa.DecodeError
+	if err := voxa.Unmarshal(encoded, &[]int16{}); !errors.As(err, &decodeErr) || decodeErr.Path != "[1]" {
+		tests.Info("Received: %+q", err)
+		tests.Failed("Should have located error at failing element")
+	}
+	tests.Passed("Should have located error at failing element")
+
+	encoded, err = voxa.Marshal([]float64{-128, 3})
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded list")
+	}
+
+	var res []int8
+	if err := voxa.Unmarshal(encoded, &res); err != nil || !reflect.DeepEqual(res, []int8{-128, 3}) {
+		tests.Info("Received: %#v and %+q", res, err)
+		tests.Failed("Should have converted values which fit into elements")
+	}
+	tests.Passed("Should have converted values which fit into elements")
+}
@@ -0,0 +1,225 @@
+package codecs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/wirekit/voxa"
+)
+
+var (
+	// ErrNotMap is returned when data slice provided is not a voxa.Map type.
+	ErrNotMap = errors.New("data item is not a Map")
+
+	// ErrMapEntryMissingValue is returned when a map entry has a key frame
+	// without a following value frame.
+	ErrMapEntryMissingValue = errors.New("map entry has no value frame")
+)
+
+const (
+	// mapKeyID and mapValueID are the FieldIDs of the key and value frames
+	// of a map entry.
+	mapKeyID   voxa.FieldID = 0
+	mapValueID voxa.FieldID = 1
+)
+
+// MapCodec encodes maps as a Map frame, where every entry is a key frame
+// followed by it's value frame, hence keys of any type with a codec, such
+// as strings, integers and other scalars, are preserved. Entries are sorted
+// by their encoded keys, so equal maps always produce equal bytes.
+type MapCodec struct{}
+
+func (mc MapCodec) BinaryToNative(b []byte, target interface{}) error {
+	d := newDecodeState(b, voxa.DefaultLimits())
+
+	frame, content, _, err := NextFrame(b)
+	if err != nil {
+		return d.fail(b, err)
+	}
+
+	if atom := voxa.Atom(content[0]); atom != voxa.Map {
+		return &voxa.DecodeError{Expected: voxa.Map, Actual: atom, Err: ErrNotMap}
+	}
+
+	var itemVal reflect.Value
+	if itval, ok := target.(reflect.Value); ok {
+		itemVal = itval
+	} else {
+		itemVal = reflect.ValueOf(target)
+	}
+
+	if itemVal.Kind() != reflect.Ptr {
+		return ErrMustBePointer
+	}
+
+	itemVal = itemVal.Elem()
+	if itemVal.Kind() != reflect.Map && itemVal.Kind() != reflect.Interface {
+		return errors.New("only map types acceptable")
+	}
+
+	return d.decodeValue(frame, content, itemVal)
+}
+
+// decodeMap decodes the entries within provided map content into provided
+// map value, decoding every key and value into the key and element type
+// of the map.
+func (mc MapCodec) decodeMap(d *decodeState, content []byte, dest reflect.Value) error {
+	if err := d.enter(); err != nil {
+		return err
+	}
+	defer d.leave()
+
+	_, _, entries, err := ReadHeader(content)
+	if err != nil {
+		return err
+	}
+
+	if err := d.checkBlockCount(countBinaryItems(entries) / 2); err != nil {
+		return err
+	}
+
+	mapType := dest.Type()
+	if dest.IsNil() {
+		dest.Set(reflect.MakeMap(mapType))
+	}
+
+	for len(entries) > 0 {
+		keyFrame, keyContent, rest, err := NextFrame(entries)
+		if err != nil {
+			return d.fail(entries, err)
+		}
+
+		if len(rest) == 0 {
+			return d.fail(keyFrame, ErrMapEntryMissingValue)
+		}
+
+		valueFrame, valueContent, rest, err := NextFrame(rest)
+		if err != nil {
+			return d.fail(rest, err)
+		}
+
+		key := reflect.New(mapType.Key()).Elem()
+		if err := d.decodeValue(keyFrame, keyContent, key); err != nil {
+			return err
+		}
+
+		// keys decoded into an interface may hold lists or maps which
+		// can not be used as a map key.
+		if key.Kind() == reflect.Interface && !key.IsNil() && !key.Elem().Type().Comparable() {
+			return d.fail(keyFrame, fmt.Errorf("can not use %q as a map key", key.Elem().Type()))
+		}
+
+		value := reflect.New(mapType.Elem()).Elem()
+		if err := d.decodeValue(valueFrame, valueContent, value); err != nil {
+			return withPath(err, fmt.Sprintf("[%v]", key))
+		}
+
+		dest.SetMapIndex(key, value)
+
+		// Reduce current length of slice.
+		entries = rest
+	}
+
+	return nil
+}
+
+func (mc MapCodec) NativeToBinary(b interface{}, c []byte) ([]byte, error) {
+	return mc.NativeToBinaryFrom(b, 0, c)
+}
+
+func (mc MapCodec) NativeToBinaryFrom(b interface{}, id voxa.FieldID, c []byte) ([]byte, error) {
+	item := reflect.ValueOf(b)
+	if item.Kind() == reflect.Ptr {
+		item = item.Elem()
+	}
+
+	if item.Kind() != reflect.Map {
+		return nil, errors.New("only map types acceptable")
+	}
+
+	buffer := slicePool.Get(1024)
+	defer buffer.Discard()
+
+	encoded, err := mc.encodeMap(item, id, buffer.Data[:0])
+	if err != nil {
+		return c, encodeError(err, "", item.Type())
+	}
+
+	return append(c, encoded...), nil
+}
+
+// mapEntry holds the position of an encoded entry and it's key within
+// a byte slice.
+type mapEntry struct {
+	start, keyEnd, end int
+}
+
+// encodeMap encodes provided map value as a Map frame, or a Null frame when
+// it is nil. Entries whose key or value have no codec are skipped.
+func (mc MapCodec) encodeMap(item reflect.Value, id voxa.FieldID, c []byte) ([]byte, error) {
+	if item.IsNil() {
+		return AppendNull(c, id), nil
+	}
+
+	c, start := ReserveFrame(c)
+	c = AppendHeader(c, voxa.Map, id)
+
+	if item.Len() == 0 {
+		return CloseFrame(c, start), nil
+	}
+
+	mapType := item.Type()
+	encodeKey := encoderFor(mapType.Key())
+	encodeValue := encoderFor(mapType.Elem())
+	if encodeKey == nil || encodeValue == nil {
+		return c[:start], ErrSkipErr
+	}
+
+	// entries are encoded after the frame, then copied back in the order of
+	// their encoded keys.
+	offset := len(c)
+	entries := make([]mapEntry, 0, item.Len())
+
+	var err error
+	for _, key := range item.MapKeys() {
+		entry := mapEntry{start: len(c)}
+
+		c, err = encodeKey(key, mapKeyID, c)
+		if err == ErrSkipErr {
+			continue
+		}
+		if err != nil {
+			return c[:start], encodeError(err, "", mapType.Key())
+		}
+
+		entry.keyEnd = len(c)
+
+		c, err = encodeValue(item.MapIndex(key), mapValueID, c)
+		if err == ErrSkipErr {
+			c = c[:entry.start]
+			continue
+		}
+		if err != nil {
+			return c[:start], encodeError(err, fmt.Sprintf("[%v]", key), mapType.Elem())
+		}
+
+		entry.end = len(c)
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		return bytes.Compare(c[a.start:a.keyEnd], c[b.start:b.keyEnd]) < 0
+	})
+
+	sorted := make([]byte, 0, len(c)-offset)
+	for _, entry := range entries {
+		sorted = append(sorted, c[entry.start:entry.end]...)
+	}
+
+	c = append(c[:offset], sorted...)
+	return CloseFrame(c, start), nil
+}
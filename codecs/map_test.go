@@ -0,0 +1,161 @@
+package codecs_test
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/influx6/faux/tests"
+	"github.com/wirekit/voxa"
+	"github.com/wirekit/voxa/codecs"
+)
+
+func TestMapCodec_NativeToBinary_StringKeys(t *testing.T) {
+	contents := map[string]int{"apples": 3, "oranges": -20, "pears": 400}
+
+	var codec codecs.MapCodec
+	encoded, err := codec.NativeToBinary(contents, []byte{})
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded value with map codec")
+	}
+	tests.Passed("Should have successfully encoded value with map codec")
+
+	var res map[string]int
+	if err := codec.BinaryToNative(encoded, &res); err != nil {
+		tests.FailedWithError(err, "Should have successfully decoded value with map codec")
+	}
+	tests.Passed("Should have successfully decoded value with map codec")
+
+	if !reflect.DeepEqual(res, contents) {
+		tests.Info("Received: %#v", res)
+		tests.Failed("Should have matching keys and values between input and output")
+	}
+	tests.Passed("Should have matching keys and values between input and output")
+}
+
+func TestMapCodec_NativeToBinary_IntegerKeys(t *testing.T) {
+	contents := map[int64][]string{
+		-1:   {"negative"},
+		0:    {"zero", "nothing"},
+		2000: {"thousands"},
+	}
+
+	var codec codecs.MapCodec
+	encoded, err := codec.NativeToBinary(contents, []byte{})
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded value with map codec")
+	}
+	tests.Passed("Should have successfully encoded value with map codec")
+
+	var res map[int64][]string
+	if err := codec.BinaryToNative(encoded, &res); err != nil {
+		tests.FailedWithError(err, "Should have successfully decoded value with map codec")
+	}
+	tests.Passed("Should have successfully decoded value with map codec")
+
+	if !reflect.DeepEqual(res, contents) {
+		tests.Info("Received: %#v", res)
+		tests.Failed("Should have matching keys and values between input and output")
+	}
+	tests.Passed("Should have matching keys and values between input and output")
+}
+
+func TestMapCodec_NativeToBinary_ScalarKeys(t *testing.T) {
+	contents := map[interface{}]interface{}{
+		"name": "bob",
+		10:     true,
+		2.5:    "half",
+		true:   uint16(7),
+	}
+
+	var codec codecs.MapCodec
+	encoded, err := codec.NativeToBinary(contents, []byte{})
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded value with map codec")
+	}
+	tests.Passed("Should have successfully encoded value with map codec")
+
+	var res map[interface{}]interface{}
+	if err := codec.BinaryToNative(encoded, &res); err != nil {
+		tests.FailedWithError(err, "Should have successfully decoded value with map codec")
+	}
+	tests.Passed("Should have successfully decoded value with map codec")
+
+	if !reflect.DeepEqual(res, contents) {
+		tests.Info("Received: %#v", res)
+		tests.Failed("Should have matching keys and values between input and output")
+	}
+	tests.Passed("Should have matching keys and values between input and output")
+}
+
+func TestMapCodec_NativeToBinary_Deterministic(t *testing.T) {
+	contents := map[string]string{}
+	for _, key := range []string{"a", "b", "c", "d", "e", "f", "g", "h"} {
+		contents[key] = key + key
+	}
+
+	var codec codecs.MapCodec
+	first, err := codec.NativeToBinary(contents, []byte{})
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded value with map codec")
+	}
+	tests.Passed("Should have successfully encoded value with map codec")
+
+	for i := 0; i < 10; i++ {
+		encoded, err := codec.NativeToBinary(contents, []byte{})
+		if err != nil {
+			tests.FailedWithError(err, "Should have successfully encoded value with map codec")
+		}
+
+		if !bytes.Equal(encoded, first) {
+			tests.Failed("Should have matching bytes for every encoding of the same map")
+		}
+	}
+	tests.Passed("Should have matching bytes for every encoding of the same map")
+}
+
+func TestMapCodec_NativeToBinary_StructField(t *testing.T) {
+	type inventory struct {
+		Owner  string         `id:"1"`
+		Counts map[string]int `id:"2"`
+	}
+
+	record := inventory{Owner: "bob", Counts: map[string]int{"hammers": 2, "nails": 300}}
+
+	encoded, err := voxa.Marshal(record)
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded value")
+	}
+	tests.Passed("Should have successfully encoded value")
+
+	var res inventory
+	if err := voxa.Unmarshal(encoded, &res); err != nil {
+		tests.FailedWithError(err, "Should have successfully decoded value")
+	}
+	tests.Passed("Should have successfully decoded value")
+
+	if !reflect.DeepEqual(res, record) {
+		tests.Info("Received: %#v", res)
+		tests.Failed("Should have matching elements between input and output")
+	}
+	tests.Passed("Should have matching elements between input and output")
+}
+
+func TestMapCodec_BinaryToNative_MissingValue(t *testing.T) {
+	key, err := codecs.AppendFrame(codecs.TextCodec{}, "orphan", 0, nil)
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded key")
+	}
+
+	encoded, start := codecs.ReserveFrame(nil)
+	encoded = codecs.AppendHeader(encoded, voxa.Map, 0)
+	encoded = codecs.CloseFrame(append(encoded, key...), start)
+
+	var res map[string]string
+	if err := (codecs.MapCodec{}).BinaryToNative(encoded, &res); !errors.Is(err, codecs.ErrMapEntryMissingValue) {
+		tests.Info("Received: %+q", err)
+		tests.Failed("Should have failed to decode map entry without value")
+	}
+	tests.Passed("Should have failed to decode map entry without value")
+}
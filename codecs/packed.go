@@ -0,0 +1,374 @@
+package codecs
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+
+	"github.com/wirekit/voxa"
+)
+
+//******************************************
+// Packed Functions
+//******************************************
+
+// Slices and arrays of numbers and booleans are encoded as a Packed frame
+// with format `[Packed][FieldID VarInt][Element Atom][Count VarInt][Values...]`,
+// where the values have no header of their own. Boolean, Int8 and UInt8
+// values take a single byte, Int16 and UInt16 values two big endian bytes,
+// Float32 and Float64 values four and eight big endian bytes, Complex64 and
+// Complex128 values two of these floats, and all others a varint, which is
+// zigzag encoded for the SInt, SInt32 and SInt64 Atoms. The functions below
+// are used both within the codecs and by code generated with voxagen.
+
+// ErrMalformedPacked is returned when the values of a Packed frame do not
+// match it's element Atom and count.
+var ErrMalformedPacked = errors.New("packed values do not match their atom and count")
+
+// ReservePacked appends the start of a Packed frame holding provided number
+// of values of provided element Atom into provided byte slice, returning the
+// byte slice with new length and the start of the frame, which must be
+// passed to CloseFrame once the values have been appended.
+func ReservePacked(c []byte, elem voxa.Atom, id voxa.FieldID, count int) ([]byte, int) {
+	c, start := ReserveFrame(c)
+	c = append(AppendHeader(c, voxa.Packed, id), byte(elem))
+	return appendVarInt(c, uint64(count)), start
+}
+
+// AppendPackedInt appends provided value as a packed value of provided
+// signed element Atom into provided byte slice.
+func AppendPackedInt(c []byte, elem voxa.Atom, v int64) []byte {
+	switch elem {
+	case voxa.Int8:
+		return append(c, byte(v))
+	case voxa.Int16:
+		return append(c, byte(v>>8), byte(v))
+	}
+	return appendVarInt(c, EncodeZigZag64(v))
+}
+
+// AppendPackedUint appends provided value as a packed value of provided
+// unsigned element Atom into provided byte slice.
+func AppendPackedUint(c []byte, elem voxa.Atom, v uint64) []byte {
+	switch elem {
+	case voxa.UInt8:
+		return append(c, byte(v))
+	case voxa.UInt16:
+		return append(c, byte(v>>8), byte(v))
+	}
+	return appendVarInt(c, v)
+}
+
+// AppendPackedFloat appends provided value as a packed value of provided
+// float element Atom into provided byte slice.
+func AppendPackedFloat(c []byte, elem voxa.Atom, v float64) []byte {
+	if elem == voxa.Float32 {
+		var buf [4]byte
+		binary.BigEndian.PutUint32(buf[:], math.Float32bits(float32(v)))
+		return append(c, buf[:]...)
+	}
+
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], math.Float64bits(v))
+	return append(c, buf[:]...)
+}
+
+// AppendPackedBool appends provided value as a packed Boolean value into
+// provided byte slice.
+func AppendPackedBool(c []byte, v bool) []byte {
+	if v {
+		return append(c, on)
+	}
+	return append(c, off)
+}
+
+// appendVarInt appends the varint encoding of x into provided byte slice.
+func appendVarInt(c []byte, x uint64) []byte {
+	for x >= 0x80 {
+		c = append(c, byte(x)|0x80)
+		x >>= 7
+	}
+	return append(c, byte(x))
+}
+
+// packedAtom returns the element Atom of a packed slice or array with
+// provided element type, which must be a number or boolean encoded by it's
+// kind rather than by one of it's methods.
+func packedAtom(elem reflect.Type) (voxa.Atom, bool) {
+	switch elem.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+	default:
+		return voxa.Invalid, false
+	}
+
+	// the method set of a pointer includes the methods of it's element.
+	ptr := reflect.PtrTo(elem)
+	if ptr.Implements(marshalerType) || ptr.Implements(binaryMarshalerType) || ptr.Implements(textMarshalerType) {
+		return voxa.Invalid, false
+	}
+	return atomFor(elem), true
+}
+
+// packedWidth returns the number of bytes taken by a packed value of
+// provided element Atom, which is 0 for varints, or false if the Atom can
+// not be packed.
+func packedWidth(elem voxa.Atom) (int, bool) {
+	switch elem {
+	case voxa.Boolean, voxa.Int8, voxa.UInt8:
+		return 1, true
+	case voxa.Int16, voxa.UInt16:
+		return 2, true
+	case voxa.Float32:
+		return 4, true
+	case voxa.Float64, voxa.Complex64:
+		return 8, true
+	case voxa.Complex128:
+		return 16, true
+	case voxa.UInt, voxa.UInt32, voxa.UInt64, voxa.SInt, voxa.SInt32, voxa.SInt64:
+		return 0, true
+	}
+	return 0, false
+}
+
+// readPacked returns the element Atom, count and values of provided Packed
+// frame content, failing when the values can not hold count values.
+func readPacked(content []byte) (voxa.Atom, int, []byte, error) {
+	_, _, data, err := ReadHeader(content)
+	if err != nil {
+		return voxa.Invalid, 0, nil, err
+	}
+
+	if len(data) == 0 {
+		return voxa.Invalid, 0, nil, ErrMalformedPacked
+	}
+
+	elem := voxa.Atom(data[0])
+	width, ok := packedWidth(elem)
+	if !ok {
+		return elem, 0, nil, fmt.Errorf("%s values can not be packed: %w", elem, ErrMalformedPacked)
+	}
+
+	count, n := DecodeVarInt64(data[1:])
+	if n == 0 {
+		return elem, 0, nil, ErrMalformedPacked
+	}
+
+	// every value takes at least a byte, which bounds count before any
+	// allocation is made for it.
+	values := data[1+n:]
+	if count > uint64(len(values)) || (width > 0 && count*uint64(width) != uint64(len(values))) {
+		return elem, 0, nil, ErrMalformedPacked
+	}
+
+	return elem, int(count), values, nil
+}
+
+// nextPacked returns the first of provided values of provided element Atom
+// and the values following it. Signed values are returned as the bits of a
+// int64, floats as the bits of a float64 and booleans as 0 or 1.
+func nextPacked(elem voxa.Atom, values []byte) (uint64, []byte, error) {
+	switch elem {
+	case voxa.Boolean:
+		if values[0] != on && values[0] != off {
+			return 0, nil, errors.New("bytes slice must either be 1 or 0")
+		}
+		return uint64(values[0]), values[1:], nil
+	case voxa.Int8:
+		return uint64(int8(values[0])), values[1:], nil
+	case voxa.UInt8:
+		return uint64(values[0]), values[1:], nil
+	case voxa.Int16:
+		return uint64(int16(DecodeUInt16(values))), values[2:], nil
+	case voxa.UInt16:
+		return uint64(DecodeUInt16(values)), values[2:], nil
+	case voxa.Float32:
+		return math.Float64bits(float64(math.Float32frombits(binary.BigEndian.Uint32(values)))), values[4:], nil
+	case voxa.Float64:
+		return binary.BigEndian.Uint64(values), values[8:], nil
+	}
+
+	x, n := DecodeVarInt64(values)
+	if n == 0 {
+		return 0, nil, ErrMalformedPacked
+	}
+
+	switch elem {
+	case voxa.SInt, voxa.SInt32, voxa.SInt64:
+		x = uint64(DecodeZigZag64(x))
+	}
+	return x, values[n:], nil
+}
+
+// packedValue returns the value of provided bits returned by nextPacked as
+// the type the scalar codecs decode provided element Atom into.
+func packedValue(elem voxa.Atom, bits uint64) interface{} {
+	switch elem {
+	case voxa.Boolean:
+		return bits == 1
+	case voxa.Int8:
+		return int8(bits)
+	case voxa.Int16:
+		return int16(bits)
+	case voxa.SInt:
+		return int(bits)
+	case voxa.SInt32:
+		return int32(bits)
+	case voxa.SInt64:
+		return int64(bits)
+	case voxa.UInt:
+		return uint(bits)
+	case voxa.UInt8:
+		return uint8(bits)
+	case voxa.UInt16:
+		return uint16(bits)
+	case voxa.UInt32:
+		return uint32(bits)
+	case voxa.Float32:
+		return float32(math.Float64frombits(bits))
+	case voxa.Float64:
+		return math.Float64frombits(bits)
+	}
+	return bits
+}
+
+// canUnpack returns true if values of provided element Atom can be decoded
+// into provided type, converting between numeric types as assignValue does.
+func canUnpack(elem voxa.Atom, t reflect.Type) bool {
+	if t.Kind() == reflect.Interface {
+		return t.NumMethod() == 0
+	}
+
+	// a Unmarshaler can only decode a frame of it's own.
+	if reflect.PtrTo(t).Implements(unmarshalerType) {
+		return false
+	}
+
+	switch elem {
+	case voxa.Boolean:
+		return t.Kind() == reflect.Bool
+	case voxa.Complex64, voxa.Complex128:
+		return isComplexKind(t.Kind())
+	}
+	return isNumericKind(t.Kind())
+}
+
+// setPacked sets provided bits returned by nextPacked into dest, which must
+// be accepted by canUnpack, failing as setNumber does for numbers which do
+// not fit into dest.
+func setPacked(dest reflect.Value, elem voxa.Atom, bits uint64) error {
+	switch dest.Kind() {
+	case reflect.Bool:
+		dest.SetBool(bits == 1)
+		return nil
+	case reflect.Interface:
+		dest.Set(reflect.ValueOf(packedValue(elem, bits)))
+		return nil
+	}
+	return setNumber(dest, reflect.ValueOf(packedValue(elem, bits)))
+}
+
+// unpack decodes the first of provided values of provided element Atom into
+// dest, which must be accepted by canUnpack, returning the values following
+// it.
+func unpack(dest reflect.Value, elem voxa.Atom, values []byte) ([]byte, error) {
+	switch elem {
+	case voxa.Complex64, voxa.Complex128:
+		value, rest := nextComplex(elem, values)
+		if dest.Kind() != reflect.Interface {
+			dest.SetComplex(value)
+		} else if elem == voxa.Complex64 {
+			dest.Set(reflect.ValueOf(complex64(value)))
+		} else {
+			dest.Set(reflect.ValueOf(value))
+		}
+		return rest, nil
+	}
+
+	bits, rest, err := nextPacked(elem, values)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := setPacked(dest, elem, bits); err != nil {
+		return nil, err
+	}
+	return rest, nil
+}
+
+// decodePacked decodes provided Packed frame content into provided slice or
+// array value, failing when the length of an array does not match the
+// number of values. Slices are only set once all values are decoded.
+func (lc ListCodec) decodePacked(d *decodeState, frame []byte, content []byte, dest reflect.Value) error {
+	elem, count, values, err := readPacked(content)
+	if err != nil {
+		return err
+	}
+
+	elemType := dest.Type().Elem()
+	if !canUnpack(elem, elemType) {
+		return d.mismatch(frame, elem, elemType)
+	}
+
+	target := dest
+	if dest.Kind() == reflect.Array {
+		if count != dest.Len() {
+			return fmt.Errorf("can not decode %d items into %q: %w", count, dest.Type(), ErrArrayLength)
+		}
+	} else {
+		if err := d.checkCount(count, elemType); err != nil {
+			return err
+		}
+		target = reflect.MakeSlice(dest.Type(), count, count)
+	}
+
+	for i := 0; i < count; i++ {
+		if values, err = unpack(target.Index(i), elem, values); err != nil {
+			return withPath(d.fail(frame, err), fmt.Sprintf("[%d]", i))
+		}
+	}
+
+	if len(values) != 0 {
+		return ErrMalformedPacked
+	}
+
+	if dest.Kind() == reflect.Slice {
+		dest.Set(target)
+	}
+	return nil
+}
+
+// encodePacked encodes provided slice or array value, whose elements are
+// of provided element Atom, as a Packed frame.
+func encodePacked(item reflect.Value, elem voxa.Atom, id voxa.FieldID, c []byte) []byte {
+	total := item.Len()
+	c, start := ReservePacked(c, elem, id, total)
+
+	switch item.Type().Elem().Kind() {
+	case reflect.Bool:
+		for i := 0; i < total; i++ {
+			c = AppendPackedBool(c, item.Index(i).Bool())
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		for i := 0; i < total; i++ {
+			c = AppendPackedInt(c, elem, item.Index(i).Int())
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		for i := 0; i < total; i++ {
+			c = AppendPackedUint(c, elem, item.Index(i).Uint())
+		}
+	case reflect.Float32, reflect.Float64:
+		for i := 0; i < total; i++ {
+			c = AppendPackedFloat(c, elem, item.Index(i).Float())
+		}
+	case reflect.Complex64, reflect.Complex128:
+		for i := 0; i < total; i++ {
+			c = AppendPackedComplex(c, elem, item.Index(i).Complex())
+		}
+	}
+
+	return CloseFrame(c, start)
+}
@@ -0,0 +1,676 @@
+package codecs
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wirekit/voxa"
+)
+
+var (
+	timeType            = reflect.TypeOf(time.Time{})
+	unknownType         = reflect.TypeOf(voxa.Unknown(nil))
+	durationType        = reflect.TypeOf(time.Duration(0))
+	marshalerType       = reflect.TypeOf((*voxa.Marshaler)(nil)).Elem()
+	unmarshalerType     = reflect.TypeOf((*voxa.Unmarshaler)(nil)).Elem()
+	binaryMarshalerType = reflect.TypeOf((*encoding.BinaryMarshaler)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+	// plans caches the compiled structPlan of a struct type, keyed by
+	// it's reflect.Type.
+	plans sync.Map
+
+	// encoders caches the encodeFunc of a type, keyed by it's reflect.Type.
+	encoders sync.Map
+)
+
+// encodeFunc encodes provided value as a frame marked with provided
+// FieldID, appending it into provided byte slice. It must return the
+// byte slice at it's original length when an error occurs.
+type encodeFunc func(reflect.Value, voxa.FieldID, []byte) ([]byte, error)
+
+// fieldPlan holds the details of a struct field which are needed to
+// encode and decode it.
+type fieldPlan struct {
+	id     voxa.FieldID
+	name   string
+	typ    reflect.Type
+	atom   voxa.Atom
+	encode encodeFunc
+
+	// index is the index sequence of the field within the struct, which
+	// holds more than one index for fields promoted from embedded structs.
+	index []int
+
+	// omitEmpty skips the field when it holds it's zero value.
+	omitEmpty bool
+
+	// required fails decoding a record missing the field.
+	required bool
+
+	// def holds the parsed value of the `default` tag of the field, which
+	// is invalid when the field has none.
+	def reflect.Value
+}
+
+// structPlan holds the compiled fields of a struct type, which are
+// used by the RecordCodec to encode and decode the struct without
+// parsing it's tags on every call.
+type structPlan struct {
+	fields []fieldPlan
+	byID   map[voxa.FieldID]int
+	byName map[string]int
+	err    error
+
+	// defaults holds the index within fields of every field with a
+	// `default` tag.
+	defaults []int
+
+	// required is the number of fields with the required option.
+	required int
+
+	// unknown is the index sequence of the voxa.Unknown field, which is
+	// nil when the struct has none.
+	unknown []int
+
+	// schema holds the HeaderCodec entries of the fields, each as a frame,
+	// used by the RecordCodec when describing records.
+	schema []byte
+}
+
+// fieldError returns a *voxa.FieldError holding the required fields not
+// marked in provided seen slice, which is indexed like fields, and provided
+// unknown FieldIDs, or nil when there are none.
+func (sp *structPlan) fieldError(seen []bool, unknown []voxa.FieldID) error {
+	var missing []voxa.FieldID
+	for i, field := range sp.fields {
+		if field.required && !seen[i] {
+			missing = append(missing, field.id)
+		}
+	}
+
+	if len(missing) == 0 && len(unknown) == 0 {
+		return nil
+	}
+	return &voxa.FieldError{Missing: missing, Unknown: unknown}
+}
+
+// planFor returns the structPlan for provided struct type, compiling and
+// caching it on first use. It is safe for concurrent use.
+func planFor(t reflect.Type) (*structPlan, error) {
+	if cached, ok := plans.Load(t); ok {
+		plan := cached.(*structPlan)
+		return plan, plan.err
+	}
+
+	cached, _ := plans.LoadOrStore(t, compilePlan(t))
+	plan := cached.(*structPlan)
+	return plan, plan.err
+}
+
+func compilePlan(t reflect.Type) *structPlan {
+	plan := &structPlan{byID: map[voxa.FieldID]int{}, byName: map[string]int{}}
+
+	fields, err := plan.collectFields(t, nil, map[reflect.Type]bool{})
+	if err != nil {
+		plan.err = err
+		return plan
+	}
+
+	for _, fp := range dominantFields(fields) {
+		if _, ok := plan.byID[fp.id]; ok {
+			plan.err = fmt.Errorf("field %q for %q: %w", fp.name, t.String(), ErrTagMustBeUniqueToField)
+			return plan
+		}
+
+		if fp.def.IsValid() {
+			plan.defaults = append(plan.defaults, len(plan.fields))
+		}
+		if fp.required {
+			plan.required++
+		}
+
+		plan.byID[fp.id] = len(plan.fields)
+		plan.byName[fp.name] = len(plan.fields)
+		plan.fields = append(plan.fields, fp)
+	}
+
+	for _, field := range plan.fields {
+		var start int
+		plan.schema, start = ReserveFrame(plan.schema)
+		plan.schema, plan.err = headerCodec.FieldToBinary(field.name, field.id, field.atom, plan.schema)
+		if plan.err != nil {
+			return plan
+		}
+		plan.schema = CloseFrame(plan.schema, start)
+	}
+
+	return plan
+}
+
+// collectFields returns the fieldPlan of every field of provided struct
+// type, whose index sequence within the planned struct is provided index.
+// The fields of embedded structs without a tag are promoted into the
+// struct, unless their type is within visiting, which holds the embedded
+// struct types being collected.
+func (sp *structPlan) collectFields(t reflect.Type, index []int, visiting map[reflect.Type]bool) ([]fieldPlan, error) {
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	var fields []fieldPlan
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag, options := splitTag(field.Tag.Get(voxa.IDTagName))
+		fieldIndex := append(append([]int(nil), index...), i)
+
+		if field.Anonymous && tag == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+
+			if embedded.Kind() == reflect.Struct {
+				// pointers to unexported structs can not be allocated when
+				// decoding, hence are skipped with their fields.
+				if visiting[embedded] || (field.PkgPath != "" && field.Type.Kind() == reflect.Ptr) {
+					continue
+				}
+
+				promoted, err := sp.collectFields(embedded, fieldIndex, visiting)
+				if err != nil {
+					return nil, err
+				}
+				fields = append(fields, promoted...)
+				continue
+			}
+		}
+
+		// unexported fields can not be encoded or set, hence are skipped.
+		if field.PkgPath != "" {
+			if tag != "" && tag != "-" {
+				return nil, fmt.Errorf("field %q for %q is unexported and can not have a 'id' tag", field.Name, t.String())
+			}
+			continue
+		}
+
+		// if tag is a dash then skip field, unless it holds unknown fields.
+		if tag == "-" {
+			if field.Type == unknownType {
+				if sp.unknown != nil {
+					return nil, fmt.Errorf("field %q for %q is a second voxa.Unknown field", field.Name, t.String())
+				}
+				sp.unknown = fieldIndex
+			}
+			continue
+		}
+
+		if tag == "" {
+			return nil, fmt.Errorf("field %q for %q requires a 'id' tag", field.Name, t.String())
+		}
+
+		fp := fieldPlan{
+			name:   field.Name,
+			index:  fieldIndex,
+			typ:    field.Type,
+			atom:   atomFor(field.Type),
+			encode: encoderFor(field.Type),
+		}
+
+		for _, option := range options {
+			switch option {
+			case voxa.OmitEmptyOption:
+				fp.omitEmpty = true
+			case voxa.RequiredOption:
+				fp.required = true
+			default:
+				return nil, fmt.Errorf("field %q for %q has unknown id tag option %q", field.Name, t.String(), option)
+			}
+		}
+
+		if def, ok := field.Tag.Lookup(voxa.DefaultTagName); ok {
+			if fp.omitEmpty {
+				return nil, fmt.Errorf("field %q for %q: %w", field.Name, t.String(), ErrTagOmitEmptyDefault)
+			}
+
+			var err error
+			if fp.def, err = parseDefault(field.Type, def); err != nil {
+				return nil, fmt.Errorf("field %q for %q has invalid default: %s", field.Name, t.String(), err)
+			}
+		}
+
+		tagValue, err := strconv.ParseUint(tag, 10, 32)
+		if err != nil {
+			if numErr, ok := err.(*strconv.NumError); ok && numErr.Err == strconv.ErrRange {
+				return nil, fmt.Errorf("field %q for %q: %w", field.Name, t.String(), ErrTagOutOfRange)
+			}
+			return nil, fmt.Errorf("field %q for %q: %w", field.Name, t.String(), ErrTagMustBeNumber)
+		}
+
+		fp.id = voxa.FieldID(tagValue)
+		fields = append(fields, fp)
+	}
+
+	return fields, nil
+}
+
+// dominantFields returns provided fields without those hidden by another
+// field of the same name, as Go does for promoted fields: a field hides
+// deeper fields of it's name, while fields of the same name and depth
+// hide each other.
+func dominantFields(fields []fieldPlan) []fieldPlan {
+	depths := map[string]int{}
+	counts := map[string]int{}
+	for _, field := range fields {
+		depth, ok := depths[field.name]
+		switch {
+		case !ok || len(field.index) < depth:
+			depths[field.name] = len(field.index)
+			counts[field.name] = 1
+		case len(field.index) == depth:
+			counts[field.name]++
+		}
+	}
+
+	dominant := fields[:0]
+	for _, field := range fields {
+		if len(field.index) == depths[field.name] && counts[field.name] == 1 {
+			dominant = append(dominant, field)
+		}
+	}
+	return dominant
+}
+
+// fieldValue returns the field of provided struct value at provided index
+// sequence, or false when the field is within a nil embedded pointer.
+func fieldValue(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// settableField returns the field of provided struct value at provided
+// index sequence, allocating the nil embedded pointers it is within.
+func settableField(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
+
+// splitTag splits provided id tag into it's id and comma separated options.
+func splitTag(tag string) (string, []string) {
+	parts := strings.Split(tag, ",")
+	return parts[0], parts[1:]
+}
+
+// parseDefault parses provided `default` tag value into a value of provided
+// type, which must be a bool, number, string, time.Duration or time.Time, or
+// a pointer to one, in which case the value is of the pointed to type.
+func parseDefault(t reflect.Type, def string) (reflect.Value, error) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	var value interface{}
+	var err error
+	switch {
+	case t == timeType:
+		value, err = time.Parse(time.RFC3339Nano, def)
+	case t == durationType:
+		value, err = time.ParseDuration(def)
+	default:
+		switch t.Kind() {
+		case reflect.Bool:
+			value, err = strconv.ParseBool(def)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			value, err = strconv.ParseInt(def, 10, t.Bits())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			value, err = strconv.ParseUint(def, 10, t.Bits())
+		case reflect.Float32, reflect.Float64:
+			value, err = strconv.ParseFloat(def, t.Bits())
+		case reflect.Complex64, reflect.Complex128:
+			value, err = strconv.ParseComplex(def, t.Bits())
+		case reflect.String:
+			value = def
+		default:
+			return reflect.Value{}, fmt.Errorf("type %q can not have a default", t)
+		}
+	}
+
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	return reflect.ValueOf(value).Convert(t), nil
+}
+
+// setDefault sets provided default value into dest, allocating a new value
+// for pointers.
+func setDefault(dest reflect.Value, def reflect.Value) {
+	if dest.Kind() == reflect.Ptr {
+		ptr := reflect.New(dest.Type().Elem())
+		ptr.Elem().Set(def)
+		def = ptr
+	}
+	dest.Set(def)
+}
+
+// SetDefault parses provided `default` tag value into the value pointed to
+// by target, as the RecordCodec does for fields missing from a record.
+func SetDefault(target interface{}, def string) error {
+	dest := reflect.ValueOf(target)
+	if dest.Kind() != reflect.Ptr || dest.IsNil() {
+		return ErrMustBePointer
+	}
+
+	value, err := parseDefault(dest.Type().Elem(), def)
+	if err != nil {
+		return err
+	}
+
+	setDefault(dest.Elem(), value)
+	return nil
+}
+
+// isEmptyValue returns true if provided value is the zero value of it's
+// type, or a empty slice or map. Times are empty when they are the zero
+// time in any location.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map, reflect.String:
+		return v.Len() == 0
+	case reflect.Struct:
+		if v.Type() == timeType {
+			return v.Interface().(time.Time).IsZero()
+		}
+	}
+	return v.IsZero()
+}
+
+// IsEmpty returns true if provided value would be skipped by a field with
+// the omitempty option.
+func IsEmpty(v interface{}) bool {
+	value := reflect.ValueOf(v)
+	return !value.IsValid() || isEmptyValue(value)
+}
+
+// atomFor returns the Atom a value of provided type is encoded with, or
+// voxa.Invalid if it's Atom can only be known from it's value.
+func atomFor(t reflect.Type) voxa.Atom {
+	if t == timeType {
+		return voxa.Time
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return voxa.Boolean
+	case reflect.Int:
+		return voxa.SInt
+	case reflect.Int8:
+		return voxa.Int8
+	case reflect.Int16:
+		return voxa.Int16
+	case reflect.Int32:
+		return voxa.SInt32
+	case reflect.Int64:
+		return voxa.SInt64
+	case reflect.Uint:
+		return voxa.UInt
+	case reflect.Uint8:
+		return voxa.UInt8
+	case reflect.Uint16:
+		return voxa.UInt16
+	case reflect.Uint32:
+		return voxa.UInt32
+	case reflect.Uint64:
+		return voxa.UInt64
+	case reflect.Float32:
+		return voxa.Float32
+	case reflect.Float64:
+		return voxa.Float64
+	case reflect.Complex64:
+		return voxa.Complex64
+	case reflect.Complex128:
+		return voxa.Complex128
+	case reflect.String:
+		return voxa.Text
+	case reflect.Slice:
+		if isByteSlice(t) {
+			return voxa.Bytes
+		}
+		if _, ok := packedAtom(t.Elem()); ok {
+			return voxa.Packed
+		}
+		return voxa.List
+	case reflect.Array:
+		if isByteArray(t) {
+			return voxa.Bytes
+		}
+		if _, ok := packedAtom(t.Elem()); ok {
+			return voxa.Packed
+		}
+		return voxa.List
+	case reflect.Struct:
+		return voxa.Record
+	case reflect.Map:
+		return voxa.Map
+	case reflect.Ptr:
+		return atomFor(t.Elem())
+	}
+
+	return voxa.Invalid
+}
+
+// isByteArray returns true if provided type is an array of bytes, which is
+// encoded as a Bytes frame instead of a list.
+func isByteArray(t reflect.Type) bool {
+	return t.Kind() == reflect.Array && t.Elem().Kind() == reflect.Uint8
+}
+
+// isByteSlice returns true if provided type is a slice of bytes, which is
+// encoded as a Bytes frame as byte arrays are.
+func isByteSlice(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8
+}
+
+// encoderFor returns the encodeFunc for provided type, building and caching
+// it on first use. It returns nil if the type has no codec.
+func encoderFor(t reflect.Type) encodeFunc {
+	if cached, ok := encoders.Load(t); ok {
+		return cached.(encodeFunc)
+	}
+
+	encode := newEncoder(t)
+	encoders.Store(t, encode)
+	return encode
+}
+
+// newEncoder returns the encodeFunc for provided type. A voxa.Marshaler takes
+// precedence over all other encodings, followed by pointers which are
+// encoded as the value they point to, the encoding of time.Time, then a
+// encoding.BinaryMarshaler or encoding.TextMarshaler as a fallback, and
+// finally the encoding matching the type's kind.
+func newEncoder(t reflect.Type) encodeFunc {
+	switch {
+	case t.Implements(marshalerType):
+		return encodeMarshaler
+	case t.Kind() == reflect.Ptr:
+		// a pointer shares the methods of it's element, hence a *time.Time
+		// would otherwise be encoded by it's MarshalBinary method.
+		return encodeElem
+	case t == timeType:
+		return scalarEncoder(timeCodec)
+	case t.Implements(binaryMarshalerType):
+		return encodeBinaryMarshaler
+	case t.Implements(textMarshalerType):
+		return encodeTextMarshaler
+	}
+
+	kindEncoder := newKindEncoder(t)
+
+	// types whose methods are declared on a pointer receiver can only be
+	// used when the value is addressable, such as a struct field or a slice
+	// element.
+	if t.Kind() != reflect.Ptr {
+		ptr := reflect.PtrTo(t)
+		switch {
+		case ptr.Implements(marshalerType):
+			return addrEncoder(encodeMarshaler, kindEncoder)
+		case ptr.Implements(binaryMarshalerType):
+			return addrEncoder(encodeBinaryMarshaler, kindEncoder)
+		case ptr.Implements(textMarshalerType):
+			return addrEncoder(encodeTextMarshaler, kindEncoder)
+		}
+	}
+
+	return kindEncoder
+}
+
+func newKindEncoder(t reflect.Type) encodeFunc {
+	switch t.Kind() {
+	case reflect.Bool:
+		return scalarEncoder(boolCodec)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Int,
+		reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return scalarEncoder(intCodec)
+	case reflect.String:
+		return scalarEncoder(textCodec)
+	case reflect.Float32, reflect.Float64:
+		return scalarEncoder(floatCodec)
+	case reflect.Complex64, reflect.Complex128:
+		return scalarEncoder(complexCodec)
+	case reflect.Struct:
+		return recordCodec.encodeStruct
+	case reflect.Map:
+		return mapCodec.encodeMap
+	case reflect.Slice:
+		if isByteSlice(t) {
+			return encodeBytes
+		}
+		return listCodec.encodeList
+	case reflect.Array:
+		if isByteArray(t) {
+			return encodeBytes
+		}
+		return listCodec.encodeList
+	case reflect.Ptr, reflect.Interface:
+		return encodeElem
+	}
+
+	return nil
+}
+
+// addrEncoder returns a encodeFunc which uses addrEncode on the address of
+// addressable values and encode on all others.
+func addrEncoder(addrEncode encodeFunc, encode encodeFunc) encodeFunc {
+	return func(v reflect.Value, id voxa.FieldID, c []byte) ([]byte, error) {
+		if v.CanAddr() {
+			return addrEncode(v.Addr(), id, c)
+		}
+		if encode == nil {
+			return c, ErrSkipErr
+		}
+		return encode(v, id, c)
+	}
+}
+
+// encodeMarshaler encodes a voxa.Marshaler using it's MarshalVoxa method.
+func encodeMarshaler(v reflect.Value, id voxa.FieldID, c []byte) ([]byte, error) {
+	if isNilValue(v) {
+		return AppendNull(c, id), nil
+	}
+
+	start := len(c)
+	encoded, err := v.Interface().(voxa.Marshaler).MarshalVoxa(id, c)
+	if err != nil {
+		return c[:start], err
+	}
+	return encoded, nil
+}
+
+// encodeBinaryMarshaler encodes a encoding.BinaryMarshaler as a Bytes frame.
+func encodeBinaryMarshaler(v reflect.Value, id voxa.FieldID, c []byte) ([]byte, error) {
+	if isNilValue(v) {
+		return AppendNull(c, id), nil
+	}
+
+	data, err := v.Interface().(encoding.BinaryMarshaler).MarshalBinary()
+	if err != nil {
+		return c, err
+	}
+
+	return AppendFrame(bytesCodec, data, id, c)
+}
+
+// encodeTextMarshaler encodes a encoding.TextMarshaler as a Text frame.
+func encodeTextMarshaler(v reflect.Value, id voxa.FieldID, c []byte) ([]byte, error) {
+	if isNilValue(v) {
+		return AppendNull(c, id), nil
+	}
+
+	text, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+	if err != nil {
+		return c, err
+	}
+
+	return AppendFrame(textCodec, string(text), id, c)
+}
+
+func isNilValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	}
+	return false
+}
+
+// scalarEncoder returns a encodeFunc which encodes values with provided codec.
+func scalarEncoder(codec voxa.Codec) encodeFunc {
+	return func(v reflect.Value, id voxa.FieldID, c []byte) ([]byte, error) {
+		return AppendFrame(codec, v.Interface(), id, c)
+	}
+}
+
+// encodeElem encodes the value a pointer points to or an interface holds,
+// or a Null frame when it is nil. Values of a registered type held by an
+// interface are encoded as a Union frame, while values without a codec held
+// by an interface, such as a channel, fail with ErrUnknownType as the type
+// of the interface does not tell them apart from other values.
+func encodeElem(v reflect.Value, id voxa.FieldID, c []byte) ([]byte, error) {
+	if v.IsNil() {
+		return AppendNull(c, id), nil
+	}
+
+	elem := v.Elem()
+	if v.Kind() == reflect.Interface {
+		if typeID, ok := voxa.TypeIDOf(elem.Type()); ok {
+			return encodeUnion(elem, typeID, id, c)
+		}
+	}
+
+	encode := encoderFor(elem.Type())
+	if encode == nil && v.Kind() == reflect.Interface {
+		return c, &voxa.EncodeError{Type: elem.Type(), Err: ErrUnknownType}
+	}
+	if encode == nil {
+		return c, ErrSkipErr
+	}
+	return encode(elem, id, c)
+}
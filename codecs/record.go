@@ -2,13 +2,10 @@ package codecs
 
 import (
 	"errors"
+	"fmt"
 
 	"reflect"
 
-	"strconv"
-
-	"fmt"
-
 	"github.com/wirekit/voxa"
 )
 
@@ -31,8 +28,17 @@ var (
 	// ErrTagMustBeNumber is returned when a tag contains more than digit values.
 	ErrTagMustBeNumber = errors.New("id tag must contain only digits")
 
-	// ErrTagCantBeMoreThanUint8 is returned when a tag contains more the max values for a uint8.
-	ErrTagCantBeMoreThanUint8 = errors.New("id tag numbers must be less or equal to a uint8 or 255")
+	// ErrTagOutOfRange is returned when a tag contains a number above voxa.MaxFieldID.
+	ErrTagOutOfRange = errors.New("id tag numbers must be less or equal to voxa.MaxFieldID")
+
+	// ErrTagOmitEmptyDefault is returned when a field has both the omitempty
+	// option and a default, as it's zero value would decode into the default.
+	ErrTagOmitEmptyDefault = errors.New("omitempty option can not be used with a default")
+
+	// ErrTagCantBeMoreThanUint8 is returned when a tag contains a number above voxa.MaxFieldID.
+	//
+	// Deprecated: ids are no longer limited to a uint8, use ErrTagOutOfRange.
+	ErrTagCantBeMoreThanUint8 = ErrTagOutOfRange
 )
 
 var (
@@ -55,28 +61,34 @@ var (
 	float64Type   = (*float64)(nil)
 )
 
-type RecordCodec struct{}
+// RecordCodec encodes structs as a Record frame, where every field is marked
+// by the FieldID of it's `id` tag.
+//
+// When Described is true, records are prefixed with a schema naming their
+// fields, hence they can be decoded by name into a map[string]interface{},
+// or into a struct whose ids have drifted from those the record was encoded
+// with. Described records are decoded by every codec.
+//
+// When DisallowUnknownFields is true, decoding fails on records holding
+// fields without a matching struct field, as voxa.Limits.DisallowUnknownFields.
+type RecordCodec struct {
+	Described             bool
+	DisallowUnknownFields bool
+}
 
 func (lc RecordCodec) BinaryToNative(b []byte, target interface{}) error {
-	xl, read := DecodeVarInt64(b)
-	if xl == 0 {
-		return ErrInvalidNoSize
-	}
+	limits := voxa.DefaultLimits()
+	limits.DisallowUnknownFields = lc.DisallowUnknownFields
 
-	headerFrame := b[:read+2]
-	dataFrame := b[read+2:]
+	d := newDecodeState(b, limits)
 
-	if len(dataFrame) < int(xl-2) {
-		return ErrInvalidDataSlice
+	frame, content, _, err := NextFrame(b)
+	if err != nil {
+		return d.fail(b, err)
 	}
 
-	//itemCount := lc.countBinaryItems(dataFrame)
-	//fmt.Printf("BinaryToNative:Items %d\n", itemCount)
-
-	header := headerFrame[read:]
-	htype := voxa.Atom(header[0])
-	if htype != voxa.Record {
-		return ErrNotRecord
+	if atom := voxa.Atom(content[0]); atom != voxa.Record && atom != voxa.Map && atom != voxa.Schema {
+		return &voxa.DecodeError{Expected: voxa.Record, Actual: atom, Err: ErrNotRecord}
 	}
 
 	var itemVal reflect.Value
@@ -102,161 +114,142 @@ func (lc RecordCodec) BinaryToNative(b []byte, target interface{}) error {
 		return errors.New("only struct and map types acceptable")
 	}
 
-	return lc.binaryToNativeWithParent(dataFrame, itemVal, item)
+	return d.decodeValue(frame, content, itemVal)
 }
 
-func (lc RecordCodec) binaryToNativeWithParent(dataFrame []byte, parent reflect.Value, pType reflect.Type) error {
-	var err error
-	for len(dataFrame) > 0 {
-		subXL, subRead := DecodeVarInt64(dataFrame)
-		if subXL == 0 {
-			return ErrInvalidNoSize
-		}
+// decodeStruct decodes the frames within provided record content into the
+// fields of provided struct value using the cached structPlan of it's type.
+// When names is not nil, frames are matched to fields by the name of their
+// FieldID within it, falling back to the FieldID for frames without a name.
+// Frames whose FieldID have no matching field are kept in the voxa.Unknown
+// field of the struct, else skipped unless unknown fields are disallowed by
+// the limits of provided decodeState.
+func (lc RecordCodec) decodeStruct(d *decodeState, content []byte, dest reflect.Value, names map[voxa.FieldID]string) error {
+	if err := d.enter(); err != nil {
+		return err
+	}
+	defer d.leave()
 
-		totalFrame := subRead + int(subXL)
-		frame := dataFrame[0:totalFrame]
-		subDataFrame := frame[subRead:]
+	plan, err := planFor(dest.Type())
+	if err != nil {
+		return err
+	}
 
-		// if giving field is not found, maybe type does not has corresponding
-		// destination, so skip.
-		hid := subDataFrame[1]
-		hidText := strconv.Itoa(int(hid))
+	_, _, dataFrame, err := ReadHeader(content)
+	if err != nil {
+		return err
+	}
 
-		// we are dealing with a sublist, then we must backtrack
-		// and ensure to have full header and body.
-		atom := voxa.Atom(subDataFrame[0])
+	// defaults are set first, hence only fields missing from the record
+	// keep them.
+	for _, index := range plan.defaults {
+		field := &plan.fields[index]
+		setDefault(settableField(dest, field.index), field.def)
+	}
 
-		var total int
-		if atom == voxa.Record || atom == voxa.List {
-			total = countBinaryItems(subDataFrame[2:])
-			subDataFrame = frame
+	// seen marks the decoded fields, which is only needed to find missing
+	// required fields.
+	var seen []bool
+	if plan.required > 0 {
+		seen = make([]bool, len(plan.fields))
+	}
+
+	// unknown frames are only those of the decoded record.
+	var kept *voxa.Unknown
+	if plan.unknown != nil {
+		kept = settableField(dest, plan.unknown).Addr().Interface().(*voxa.Unknown)
+		*kept = nil
+	}
+
+	var unknown []voxa.FieldID
+	for len(dataFrame) > 0 {
+		frame, subContent, rest, err := NextFrame(dataFrame)
+		if err != nil {
+			return d.fail(dataFrame, err)
 		}
 
-		var field reflect.StructField
+		_, id, _, err := ReadHeader(subContent)
+		if err != nil {
+			return d.fail(frame, err)
+		}
 
-		if parent.Kind() == reflect.Struct {
-			field, err = getFieldByTagAndValue(pType, voxa.IDTagName, hidText)
-			if err != nil {
-				// Reduce current length of slice.
-				dataFrame = dataFrame[totalFrame:]
-				continue
-			}
+		index, ok := plan.byID[id]
+		if name, named := names[id]; named {
+			index, ok = plan.byName[name]
 		}
 
-		// get new version value field.
-		if err := lc.binaryToNativeItem(subDataFrame, int(hid), total, atom, parent, field); err != nil && err != ErrSkipErr {
-			return err
+		// if giving field is not found, maybe type does not has corresponding
+		// destination, so skip.
+		if ok {
+			field := &plan.fields[index]
+			if err := d.decodeValue(frame, subContent, settableField(dest, field.index)); err != nil {
+				return withPath(err, field.name)
+			}
+			if seen != nil {
+				seen[index] = true
+			}
+		} else if kept != nil {
+			*kept = append(*kept, frame...)
+		} else if d.limits.DisallowUnknownFields {
+			unknown = append(unknown, id)
 		}
 
 		// Reduce current length of slice.
-		dataFrame = dataFrame[totalFrame:]
+		dataFrame = rest
 	}
 
-	return nil
+	return plan.fieldError(seen, unknown)
 }
 
-// BinaryToNativeItem attempts to convert a singular item within provided data within the byte slice
-// into the provided reflect.Value, it ensures the internal data field ID attached in the encoded
-// data match the provided. It returns an error if the id does not match, or if the value could not
-// be decoded safely, more so, the value must be settable.
-func (lc RecordCodec) binaryToNativeItem(data []byte, pos int, count int, atom voxa.Atom, parent reflect.Value, field reflect.StructField) error {
-	var dest reflect.Value
-
-	if field.Type != nil {
-		if atom != voxa.List {
-			dest = reflect.New(field.Type)
-			if dest.Kind() == reflect.Ptr {
-				dest = dest.Elem()
-			}
+// decodeMap decodes the frames within provided record content into provided
+// map value, where each value is keyed by it's FieldID. It is only used to
+// read records encoded from maps before the Map Atom existed.
+func (lc RecordCodec) decodeMap(d *decodeState, content []byte, dest reflect.Value) error {
+	if err := d.enter(); err != nil {
+		return err
+	}
+	defer d.leave()
 
-			if !dest.CanSet() {
-				return ErrValueUnsettable
-			}
-		} else {
-			dest = reflect.MakeSlice(field.Type, 0, count)
-		}
-	} else {
-		switch atom {
-		case voxa.List:
-			target := make([]interface{}, count)
-			dest = reflect.ValueOf(target)
-		case voxa.Record:
-			dest = reflect.ValueOf([]map[interface{}]interface{}{})
-		}
+	mapType := dest.Type()
+	if dest.IsNil() {
+		dest.Set(reflect.MakeMap(mapType))
 	}
 
-	switch atom {
-	case voxa.List:
-		resDest, err := listCodec.BinaryToNative(data, dest)
-		if err != nil {
-			return err
-		}
+	keyType := mapType.Key()
+	if keyType.Kind() != reflect.Interface && !isNumericKind(keyType.Kind()) {
+		return ErrUnknownTypeForMap
+	}
 
-		newDest := reflect.ValueOf(resDest)
-		if newDest.Kind() == reflect.Ptr && dest.Kind() != reflect.Ptr {
-			dest = newDest.Elem()
-		} else {
-			dest = newDest
-		}
-	case voxa.Record:
-		if err := recordCodec.BinaryToNative(data, dest); err != nil {
-			return err
-		}
-	case voxa.Text:
-		value, _, err := textCodec.BinaryToNative(data)
-		if err != nil {
-			return err
-		}
+	_, _, dataFrame, err := ReadHeader(content)
+	if err != nil {
+		return err
+	}
 
-		dest = reflect.ValueOf(value)
-	case voxa.Time:
-		value, _, err := timeCodec.BinaryToNative(data)
+	for len(dataFrame) > 0 {
+		frame, subContent, rest, err := NextFrame(dataFrame)
 		if err != nil {
-			return err
+			return d.fail(dataFrame, err)
 		}
 
-		dest = reflect.ValueOf(value)
-	case voxa.Bytes:
-		value, _, err := bytesCodec.BinaryToNative(data)
+		_, id, _, err := ReadHeader(subContent)
 		if err != nil {
-			return err
+			return d.fail(frame, err)
 		}
-		dest = reflect.ValueOf(value)
-	case voxa.Boolean:
-		value, _, err := boolCodec.BinaryToNative(data)
-		if err != nil {
-			return err
-		}
-		dest = reflect.ValueOf(value)
-	case voxa.Float64, voxa.Float32:
-		value, _, err := floatCodec.BinaryToNative(data)
-		if err != nil {
-			return err
-		}
-		dest = reflect.ValueOf(value)
-	case voxa.Int, voxa.UInt, voxa.UInt8, voxa.UInt16, voxa.UInt32, voxa.UInt64,
-		voxa.Int8, voxa.Int16, voxa.Int32, voxa.Int64:
-		value, _, err := intCodec.BinaryToNative(data)
-		if err != nil {
-			return err
-		}
-
-		dest = reflect.ValueOf(value)
-	default:
-		return errors.New("unknown type")
-	}
 
-	switch parent.Kind() {
-	case reflect.Struct:
-		ff := parent.FieldByName(field.Name)
+		value := reflect.New(mapType.Elem()).Elem()
+		if err := d.decodeValue(frame, subContent, value); err != nil {
+			return withPath(err, fmt.Sprintf("[%d]", id))
+		}
 
-		if ff.Kind() != reflect.Ptr && dest.Kind() == reflect.Ptr {
-			dest = dest.Elem()
+		key := reflect.ValueOf(int(id))
+		if keyType.Kind() != reflect.Interface {
+			key = key.Convert(keyType)
 		}
 
-		ff.Set(dest)
-	case reflect.Map:
-		parent.SetMapIndex(reflect.ValueOf(pos), dest)
+		dest.SetMapIndex(key, value)
+
+		// Reduce current length of slice.
+		dataFrame = rest
 	}
 
 	return nil
@@ -272,86 +265,84 @@ func (lc RecordCodec) NativeToBinaryFrom(b interface{}, id voxa.FieldID, c []byt
 		item = item.Elem()
 	}
 
-	if item.Kind() != reflect.Struct && item.Kind() != reflect.Map {
+	var encode encodeFunc
+	switch item.Kind() {
+	case reflect.Struct:
+		encode = lc.encodeStruct
+		if lc.Described {
+			encode = lc.encodeDescribed
+		}
+	case reflect.Map:
+		encode = mapCodec.encodeMap
+	default:
 		return nil, errors.New("only map and struct types acceptable")
 	}
 
-	itemTypeElem := item.Type()
-	//itemTypeSizeUPtr := int(itemTypeElem.Size()) * 128
-	//itemTypeConservativeSize := itemTypeSizeUPtr
-
 	buffer := slicePool.Get(1024)
 	defer buffer.Discard()
 
-	var err error
-	base := buffer.Data[:0]
+	encoded, err := encode(item, id, buffer.Data[:0])
+	if err != nil {
+		return c, encodeError(err, "", item.Type())
+	}
 
-	switch item.Kind() {
-	case reflect.Map:
-		for id, key := range item.MapKeys() {
-			indexValue := item.MapIndex(key)
-			base, err = nativeItemToBinary(indexValue.Interface(), voxa.FieldID(id+1), base)
-			if err != nil && err != ErrSkipErr {
-				return c, err
-			}
-		}
-	case reflect.Struct:
-		totalElements := item.NumField()
-		seen := map[uint64]bool{}
-		for i := 0; i < totalElements; i++ {
-			indexValue := item.Field(i)
-			indexType := itemTypeElem.Field(i)
-			tag := indexType.Tag.Get(voxa.IDTagName)
-
-			// if tag is a dash then skip field.
-			if tag == "-" {
-				continue
-			}
+	return append(c, encoded...), nil
+}
 
-			if tag == "" {
-				return c, fmt.Errorf("field %q for %q requires a 'id' tag", indexType.Name, item.String())
-			}
+// encodeStruct encodes provided struct value as a record frame using the
+// cached structPlan of it's type.
+func (lc RecordCodec) encodeStruct(item reflect.Value, id voxa.FieldID, c []byte) ([]byte, error) {
+	plan, err := planFor(item.Type())
+	if err != nil {
+		return c, err
+	}
 
-			tagValue, err := strconv.ParseUint(tag, 10, 8)
-			if err != nil {
-				if err == strconv.ErrRange {
-					return c, ErrTagCantBeMoreThanUint8
-				}
+	c, start := ReserveFrame(c)
+	c = AppendHeader(c, voxa.Record, id)
 
-				return c, ErrTagMustBeNumber
-			}
+	for i := range plan.fields {
+		field := &plan.fields[i]
+		if field.encode == nil {
+			continue
+		}
 
-			if seen[tagValue] {
-				return c, ErrTagMustBeUniqueToField
-			}
+		// fields within nil embedded pointers are left out.
+		value, ok := fieldValue(item, field.index)
+		if !ok || (field.omitEmpty && isEmptyValue(value)) {
+			continue
+		}
 
-			seen[tagValue] = true
+		c, err = field.encode(value, field.id, c)
+		if err != nil && err != ErrSkipErr {
+			return c[:start], encodeError(err, field.name, field.typ)
+		}
+	}
 
-			base, err = nativeItemToBinary(indexValue.Interface(), voxa.FieldID(tagValue), base)
-			if err != nil && err != ErrSkipErr {
-				return c, err
-			}
+	if plan.unknown != nil {
+		if kept, ok := fieldValue(item, plan.unknown); ok {
+			c = append(c, kept.Bytes()...)
 		}
 	}
 
-	// payload is the total length of encoded contents + length of items count slice + 2 bits for flags.
-	c = append(c, EncodeVarInt64(uint64(len(base)+2))...)
-	c = append(c, byte(voxa.Record), byte(id))
-	c = append(c, base...)
-	return c, nil
+	return CloseFrame(c, start), nil
 }
 
-func getFieldByTagAndValue(tl reflect.Type, tag string, value string) (reflect.StructField, error) {
-	if tl.Kind() == reflect.Ptr {
-		tl = tl.Elem()
+// encodeDescribed encodes provided struct value as a Schema frame, holding
+// the schema of the struct followed by it's record frame.
+func (lc RecordCodec) encodeDescribed(item reflect.Value, id voxa.FieldID, c []byte) ([]byte, error) {
+	plan, err := planFor(item.Type())
+	if err != nil {
+		return c, err
 	}
 
-	for i := 0; i < tl.NumField(); i++ {
-		field := tl.Field(i)
-		if field.Tag.Get(tag) == value {
-			return field, nil
-		}
+	c, start := ReserveFrame(c)
+	c = AppendHeader(c, voxa.Schema, id)
+	c = append(c, plan.schema...)
+
+	c, err = lc.encodeStruct(item, 0, c)
+	if err != nil {
+		return c[:start], err
 	}
 
-	return reflect.StructField{}, errors.New("not found")
+	return CloseFrame(c, start), nil
 }
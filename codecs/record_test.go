@@ -1,13 +1,20 @@
 package codecs_test
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"math"
 	"reflect"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 
 	"time"
 
 	"github.com/influx6/faux/tests"
+	"github.com/wirekit/voxa"
 	"github.com/wirekit/voxa/codecs"
 )
 
@@ -72,6 +79,70 @@ func TestRecordCodec_NativeToBinary_StructOnly_DuplicateTags(t *testing.T) {
 	tests.Passed("Should have failed due to duplicate tag")
 }
 
+func TestRecordCodec_NativeToBinary_StructOnly_TagOutOfRange(t *testing.T) {
+	record := struct {
+		Age  int    `id:"1"`
+		Name string `id:"4294967296"`
+	}{
+		Age:  20,
+		Name: "bob",
+	}
+
+	var codec codecs.RecordCodec
+	if _, err := codec.NativeToBinary(record, []byte{}); !errors.Is(err, codecs.ErrTagOutOfRange) {
+		tests.FailedWithError(err, "Should have failed due to tag out of range")
+	}
+	tests.Passed("Should have failed due to tag out of range")
+}
+
+func TestRecordCodec_NativeToBinary_Concurrent(t *testing.T) {
+	type concurrentRecord struct {
+		Age        int       `id:"1"`
+		Name       string    `id:"2"`
+		OtherNames []Address `id:"3"`
+	}
+
+	record := concurrentRecord{
+		Age:        20,
+		Name:       "bob",
+		OtherNames: []Address{{Value: "wreckage"}, {Value: "moppers guild"}},
+	}
+
+	var codec codecs.RecordCodec
+	var wg sync.WaitGroup
+	failures := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			encoded, err := codec.NativeToBinary(record, nil)
+			if err != nil {
+				failures <- err
+				return
+			}
+
+			var res concurrentRecord
+			if err := codec.BinaryToNative(encoded, &res); err != nil {
+				failures <- err
+				return
+			}
+
+			if !reflect.DeepEqual(res, record) {
+				failures <- errors.New("decoded record does not match input")
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(failures)
+
+	for err := range failures {
+		tests.FailedWithError(err, "Should have successfully encoded and decoded record concurrently")
+	}
+	tests.Passed("Should have successfully encoded and decoded record concurrently")
+}
+
 func TestRecordCodec_NativeToBinary_StructOnly(t *testing.T) {
 	record := struct {
 		Age       int    `id:"1"`
@@ -369,3 +440,830 @@ func find(v interface{}, r []interface{}) bool {
 	}
 	return false
 }
+
+// temperature encodes itself as a Int64 frame holding hundredths of a degree.
+type temperature struct {
+	celsius float64
+}
+
+func (t temperature) MarshalVoxa(id voxa.FieldID, c []byte) ([]byte, error) {
+	var codec codecs.IntCodec
+	encoded, err := codec.NativeToBinary(int64(t.celsius*100), id, nil)
+	if err != nil {
+		return c, err
+	}
+
+	c = append(c, codecs.EncodeVarInt64(uint64(len(encoded)))...)
+	return append(c, encoded...), nil
+}
+
+func (t *temperature) UnmarshalVoxa(frame []byte) error {
+	_, read := codecs.DecodeVarInt64(frame)
+
+	var codec codecs.IntCodec
+	value, _, err := codec.BinaryToNative(frame[read:])
+	if err != nil {
+		return err
+	}
+
+	t.celsius = float64(value.(int64)) / 100
+	return nil
+}
+
+// version implements encoding.BinaryMarshaler as `[Major][Minor]`.
+type version struct {
+	major, minor uint8
+}
+
+func (v version) MarshalBinary() ([]byte, error) {
+	return []byte{v.major, v.minor}, nil
+}
+
+func (v *version) UnmarshalBinary(b []byte) error {
+	if len(b) != 2 {
+		return errors.New("version must be 2 bytes")
+	}
+	v.major, v.minor = b[0], b[1]
+	return nil
+}
+
+// color implements encoding.TextMarshaler as it's name.
+type color int
+
+func (c color) MarshalText() ([]byte, error) {
+	switch c {
+	case 1:
+		return []byte("red"), nil
+	case 2:
+		return []byte("blue"), nil
+	}
+	return nil, errors.New("unknown color")
+}
+
+func (c *color) UnmarshalText(b []byte) error {
+	switch string(b) {
+	case "red":
+		*c = 1
+	case "blue":
+		*c = 2
+	default:
+		return errors.New("unknown color")
+	}
+	return nil
+}
+
+func TestRecordCodec_NativeToBinary_Marshalers(t *testing.T) {
+	type reading struct {
+		Temperature  temperature   `id:"1"`
+		Version      version       `id:"2"`
+		Color        color         `id:"3"`
+		Temperatures []temperature `id:"4"`
+		Previous     *temperature  `id:"5"`
+	}
+
+	record := reading{
+		Temperature:  temperature{celsius: 21.5},
+		Version:      version{major: 1, minor: 4},
+		Color:        2,
+		Temperatures: []temperature{{celsius: -3.25}, {celsius: 40}},
+		Previous:     &temperature{celsius: 19.75},
+	}
+
+	var codec codecs.RecordCodec
+	encoded, err := codec.NativeToBinary(&record, []byte{})
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded value with record codec")
+	}
+	tests.Passed("Should have successfully encoded value with record codec")
+
+	var res reading
+	if err := codec.BinaryToNative(encoded, &res); err != nil {
+		tests.FailedWithError(err, "Should have successfully decoded value with record codec")
+	}
+	tests.Passed("Should have successfully decoded value with record codec")
+
+	if !reflect.DeepEqual(res, record) {
+		tests.Info("Received: %#v", res)
+		tests.Info("Expected: %#v", record)
+		tests.Failed("Should have matching elements between input and res")
+	}
+	tests.Passed("Should have matching elements between input and res")
+}
+
+func TestRecordCodec_NativeToBinary_Described(t *testing.T) {
+	type address struct {
+		Street string `id:"1"`
+	}
+
+	record := struct {
+		Username  string   `id:"1"`
+		Age       int      `id:"2"`
+		Interests []string `id:"3"`
+		Home      address  `id:"4"`
+	}{
+		Username:  "bob",
+		Age:       32,
+		Interests: []string{"daydreaming", "hacking"},
+		Home:      address{Street: "20. Classy Street"},
+	}
+
+	codec := codecs.RecordCodec{Described: true}
+	encoded, err := codec.NativeToBinary(record, []byte{})
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded value with record codec")
+	}
+	tests.Passed("Should have successfully encoded value with record codec")
+
+	var named map[string]interface{}
+	if err := codec.BinaryToNative(encoded, &named); err != nil {
+		tests.FailedWithError(err, "Should have successfully decoded value into named map")
+	}
+	tests.Passed("Should have successfully decoded value into named map")
+
+	if named["Username"] != "bob" || named["Age"] != 32 {
+		tests.Info("Received: %#v", named)
+		tests.Failed("Should have received values keyed by field name")
+	}
+
+	if !reflect.DeepEqual(named["Interests"], []interface{}{"daydreaming", "hacking"}) {
+		tests.Info("Received: %#v", named["Interests"])
+		tests.Failed("Should have received list keyed by field name")
+	}
+	tests.Passed("Should have received values keyed by field name")
+
+	// ids have drifted, but names still match.
+	var drifted struct {
+		Interests []string `id:"1"`
+		Home      address  `id:"2"`
+		Age       int      `id:"7"`
+		Username  string   `id:"9"`
+	}
+
+	if err := codec.BinaryToNative(encoded, &drifted); err != nil {
+		tests.FailedWithError(err, "Should have successfully decoded value into drifted struct")
+	}
+	tests.Passed("Should have successfully decoded value into drifted struct")
+
+	if drifted.Username != record.Username || drifted.Age != record.Age || drifted.Home != record.Home ||
+		!reflect.DeepEqual(drifted.Interests, record.Interests) {
+		tests.Info("Received: %#v", drifted)
+		tests.Failed("Should have matching elements by name between input and res")
+	}
+	tests.Passed("Should have matching elements by name between input and res")
+
+	var any interface{}
+	if err := voxa.Unmarshal(encoded, &any); err != nil {
+		tests.FailedWithError(err, "Should have successfully decoded value into interface")
+	}
+	tests.Passed("Should have successfully decoded value into interface")
+
+	if fields, ok := any.(map[string]interface{}); !ok || fields["Username"] != "bob" {
+		tests.Info("Received: %#v", any)
+		tests.Failed("Should have received map keyed by field name")
+	}
+	tests.Passed("Should have received map keyed by field name")
+}
+
+func TestRecordCodec_BinaryToNative_DecodeError(t *testing.T) {
+	type name struct {
+		Value interface{} `id:"1"`
+	}
+
+	record := struct {
+		Age        int    `id:"1"`
+		OtherNames []name `id:"4"`
+	}{
+		Age:        20,
+		OtherNames: []name{{Value: 20}, {Value: "Ross Rics"}},
+	}
+
+	var codec codecs.RecordCodec
+	encoded, err := codec.NativeToBinary(record, []byte{})
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded struct")
+	}
+
+	var res struct {
+		Age        int `id:"1"`
+		OtherNames []struct {
+			Value int `id:"1"`
+		} `id:"4"`
+	}
+
+	err = codec.BinaryToNative(encoded, &res)
+
+	var decodeErr *voxa.DecodeError
+	if !errors.As(err, &decodeErr) {
+		tests.Info("Received: %+q", err)
+		tests.Failed("Should have failed with a *voxa.DecodeError")
+	}
+	tests.Passed("Should have failed with a *voxa.DecodeError")
+
+	if !errors.Is(err, codecs.ErrUnexpectedAtom) {
+		tests.FailedWithError(err, "Should have wrapped ErrUnexpectedAtom")
+	}
+	tests.Passed("Should have wrapped ErrUnexpectedAtom")
+
+	if decodeErr.Path != "OtherNames[1].Value" {
+		tests.Info("Received: %+q", decodeErr.Path)
+		tests.Failed("Should have located error at field path")
+	}
+	tests.Passed("Should have located error at field path")
+
+	if decodeErr.Expected != voxa.SInt || decodeErr.Actual != voxa.Text {
+		tests.Info("Received: %s and %s", decodeErr.Expected, decodeErr.Actual)
+		tests.Failed("Should have reported expected and actual atoms")
+	}
+	tests.Passed("Should have reported expected and actual atoms")
+
+	_, content, _, err := codecs.NextFrame(encoded[decodeErr.Offset:])
+	if err != nil {
+		tests.FailedWithError(err, "Should have located error at a frame")
+	}
+
+	if value, err := codecs.FrameToText(content); err != nil || value != "Ross Rics" {
+		tests.Info("Received: %+q", value)
+		tests.Failed("Should have located error at offset of failing frame")
+	}
+	tests.Passed("Should have located error at offset of failing frame")
+}
+
+func TestRecordCodec_BinaryToNative_Overflow(t *testing.T) {
+	type narrow struct {
+		Small int8      `id:"1"`
+		Count uint      `id:"2"`
+		Whole int       `id:"3"`
+		Ratio float32   `id:"4"`
+		Phase complex64 `id:"5"`
+	}
+
+	for _, item := range []interface{}{
+		struct {
+			Small int64 `id:"1"`
+		}{Small: 1000},
+		struct {
+			Count int `id:"2"`
+		}{Count: -1},
+		struct {
+			Whole float64 `id:"3"`
+		}{Whole: 3.9},
+		struct {
+			Whole uint64 `id:"3"`
+		}{Whole: math.MaxUint64},
+		struct {
+			Ratio float64 `id:"4"`
+		}{Ratio: math.MaxFloat64},
+		struct {
+			Phase complex128 `id:"5"`
+		}{Phase: complex(1, math.MaxFloat64)},
+	} {
+		encoded, err := voxa.Marshal(item)
+		if err != nil {
+			tests.FailedWithError(err, "Should have successfully encoded struct")
+		}
+
+		var res narrow
+		err = voxa.Unmarshal(encoded, &res)
+
+		var decodeErr *voxa.DecodeError
+		if !errors.As(err, &decodeErr) || !errors.Is(err, codecs.ErrUnexpectedAtom) {
+			tests.Info("Received: %#v and %+q", res, err)
+			tests.Failed("Should have failed to decode %#v into narrower field", item)
+		}
+
+		if decodeErr.Expected != voxa.Invalid || !strings.Contains(err.Error(), "does not fit") {
+			tests.Info("Received: %+q", err)
+			tests.Failed("Should have reported the value as not fitting rather than as an unexpected atom")
+		}
+	}
+	tests.Passed("Should have failed to decode values which do not fit into fields")
+
+	encoded, err := voxa.Marshal(struct {
+		Small int64      `id:"1"`
+		Count int        `id:"2"`
+		Whole float64    `id:"3"`
+		Ratio float64    `id:"4"`
+		Phase complex128 `id:"5"`
+	}{Small: -128, Count: 7, Whole: 3, Ratio: 1.5, Phase: 2 - 1i})
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded struct")
+	}
+
+	var res narrow
+	if err := voxa.Unmarshal(encoded, &res); err != nil {
+		tests.FailedWithError(err, "Should have successfully decoded values which fit")
+	}
+
+	if expected := (narrow{Small: -128, Count: 7, Whole: 3, Ratio: 1.5, Phase: 2 - 1i}); res != expected {
+		tests.Info("Received: %#v", res)
+		tests.Info("Expected: %#v", expected)
+		tests.Failed("Should have converted values which fit into fields")
+	}
+	tests.Passed("Should have converted values which fit into fields")
+}
+
+type failingText struct{}
+
+func (failingText) MarshalText() ([]byte, error) {
+	return nil, errors.New("failed to marshal text")
+}
+
+func TestRecordCodec_NativeToBinary_EncodeError(t *testing.T) {
+	record := struct {
+		Age   int                      `id:"1"`
+		Notes map[string][]failingText `id:"2"`
+	}{
+		Age:   20,
+		Notes: map[string][]failingText{"first": {{}}},
+	}
+
+	var codec codecs.RecordCodec
+	_, err := codec.NativeToBinary(record, []byte{})
+
+	var encodeErr *voxa.EncodeError
+	if !errors.As(err, &encodeErr) {
+		tests.Info("Received: %+q", err)
+		tests.Failed("Should have failed with a *voxa.EncodeError")
+	}
+	tests.Passed("Should have failed with a *voxa.EncodeError")
+
+	if encodeErr.Path != "Notes[first][0]" || encodeErr.Type != reflect.TypeOf(failingText{}) {
+		tests.Info("Received: %+q of %q", encodeErr.Path, encodeErr.Type)
+		tests.Failed("Should have located error at field path")
+	}
+	tests.Passed("Should have located error at field path")
+}
+
+func TestRecordCodec_NativeToBinary_UnknownElement(t *testing.T) {
+	for path, record := range map[string]interface{}{
+		"Items[1]": struct {
+			Items []interface{} `id:"1"`
+		}{Items: []interface{}{20, make(chan int)}},
+		"Values[first]": struct {
+			Values map[string]interface{} `id:"1"`
+		}{Values: map[string]interface{}{"first": make(chan int)}},
+		"Value": struct {
+			Value interface{} `id:"1"`
+		}{Value: make(chan int)},
+	} {
+		_, err := voxa.Marshal(record)
+
+		var encodeErr *voxa.EncodeError
+		if !errors.As(err, &encodeErr) || !errors.Is(err, codecs.ErrUnknownType) {
+			tests.Info("Received: %+q", err)
+			tests.Failed("Should have failed to encode a channel held by an interface")
+		}
+
+		if encodeErr.Path != path || encodeErr.Type != reflect.TypeOf(make(chan int)) {
+			tests.Info("Received: %+q of %q", encodeErr.Path, encodeErr.Type)
+			tests.Failed("Should have located error at element path %q", path)
+		}
+	}
+	tests.Passed("Should have failed to encode channels held by an interface")
+}
+
+type status int32
+
+type label string
+
+func TestRecordCodec_NamedTypes(t *testing.T) {
+	type namedRecord struct {
+		Timeout  time.Duration    `id:"1"`
+		Status   status           `id:"2"`
+		Label    label            `id:"3"`
+		Statuses []status         `id:"4"`
+		Labels   map[status]label `id:"5"`
+	}
+
+	record := namedRecord{
+		Timeout:  3 * time.Second,
+		Status:   status(-2),
+		Label:    label("running"),
+		Statuses: []status{1, 2, 3},
+		Labels:   map[status]label{1: "started", 2: "stopped"},
+	}
+
+	var codec codecs.RecordCodec
+	encoded, err := codec.NativeToBinary(record, []byte{})
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded named types")
+	}
+	tests.Passed("Should have successfully encoded named types")
+
+	var res namedRecord
+	if err := codec.BinaryToNative(encoded, &res); err != nil {
+		tests.FailedWithError(err, "Should have successfully decoded named types")
+	}
+	tests.Passed("Should have successfully decoded named types")
+
+	if !reflect.DeepEqual(res, record) {
+		tests.Info("Received: %#v", res)
+		tests.Info("Expected: %#v", record)
+		tests.Failed("Should have matching named types between input and res")
+	}
+	tests.Passed("Should have matching named types between input and res")
+}
+
+func TestRecordCodec_Nil(t *testing.T) {
+	type optionalRecord struct {
+		Age      *int            `id:"1"`
+		Name     *string         `id:"2"`
+		Home     *Address        `id:"3"`
+		Names    []string        `id:"4"`
+		Labels   map[string]int  `id:"5"`
+		Note     interface{}     `id:"6"`
+		Children []*Address      `id:"7"`
+		Scores   map[string]*int `id:"8"`
+	}
+
+	age := 20
+	record := optionalRecord{
+		Age:      &age,
+		Children: []*Address{nil, {Value: "Lane"}},
+		Scores:   map[string]*int{"none": nil},
+	}
+
+	var codec codecs.RecordCodec
+	encoded, err := codec.NativeToBinary(record, []byte{})
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded nil values")
+	}
+	tests.Passed("Should have successfully encoded nil values")
+
+	name := "bob"
+	res := optionalRecord{Name: &name, Names: []string{"bob"}, Note: "note"}
+	if err := codec.BinaryToNative(encoded, &res); err != nil {
+		tests.FailedWithError(err, "Should have successfully decoded nil values")
+	}
+	tests.Passed("Should have successfully decoded nil values")
+
+	if !reflect.DeepEqual(res, record) {
+		tests.Info("Received: %#v", res)
+		tests.Info("Expected: %#v", record)
+		tests.Failed("Should have decoded nil values as nil")
+	}
+	tests.Passed("Should have decoded nil values as nil")
+}
+
+func TestRecordCodec_OmitEmptyAndDefault(t *testing.T) {
+	type omitted struct {
+		Name    string   `id:"1"`
+		Note    string   `id:"2,omitempty"`
+		Tags    []string `id:"3,omitempty"`
+		Enabled bool     `id:"8"`
+	}
+
+	type settings struct {
+		Name    string        `id:"1"`
+		Retries int           `id:"4" default:"3"`
+		Timeout time.Duration `id:"5" default:"1.5s"`
+		Limit   *uint16       `id:"6" default:"20"`
+		Since   time.Time     `id:"7" default:"2018-04-01T10:30:15Z"`
+		Enabled bool          `id:"8" default:"true"`
+	}
+
+	type required struct {
+		Name    string `id:"1"`
+		Enabled bool   `id:"8"`
+	}
+
+	var codec codecs.RecordCodec
+	encoded, err := codec.NativeToBinary(omitted{Name: "bob"}, []byte{})
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded record")
+	}
+	tests.Passed("Should have successfully encoded record")
+
+	expected, err := codec.NativeToBinary(required{Name: "bob"}, []byte{})
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded record")
+	}
+
+	if !bytes.Equal(encoded, expected) {
+		tests.Info("Received: %#v", encoded)
+		tests.Info("Expected: %#v", expected)
+		tests.Failed("Should have left out empty fields with omitempty")
+	}
+	tests.Passed("Should have left out empty fields with omitempty")
+
+	var res settings
+	if err := codec.BinaryToNative(encoded, &res); err != nil {
+		tests.FailedWithError(err, "Should have successfully decoded record")
+	}
+	tests.Passed("Should have successfully decoded record")
+
+	if res.Retries != 3 || res.Timeout != 1500*time.Millisecond || res.Limit == nil || *res.Limit != 20 ||
+		!res.Since.Equal(timeValue.Truncate(time.Second)) || res.Enabled {
+		tests.Info("Received: %#v", res)
+		tests.Failed("Should have set defaults of fields missing from the record only")
+	}
+	tests.Passed("Should have set defaults of fields missing from the record only")
+}
+
+func TestRecordCodec_OmitEmptyWithDefault(t *testing.T) {
+	type settings struct {
+		Retries int `id:"1,omitempty" default:"3"`
+	}
+
+	// a zero value left out of the record would decode into the default.
+	_, err := voxa.Marshal(settings{})
+	if !errors.Is(err, codecs.ErrTagOmitEmptyDefault) {
+		tests.FailedWithError(err, "Should have failed to encode field with both omitempty and a default")
+	}
+	tests.Passed("Should have failed to encode field with both omitempty and a default")
+
+	var res settings
+	if err := voxa.Unmarshal(mustMarshal(struct{}{}), &res); !errors.Is(err, codecs.ErrTagOmitEmptyDefault) {
+		tests.FailedWithError(err, "Should have failed to decode field with both omitempty and a default")
+	}
+	tests.Passed("Should have failed to decode field with both omitempty and a default")
+}
+
+func TestRecordCodec_InvalidTags(t *testing.T) {
+	type unknownOption struct {
+		Name string `id:"1,omitmissing"`
+	}
+
+	type invalidDefault struct {
+		Age int `id:"1" default:"twenty"`
+	}
+
+	var codec codecs.RecordCodec
+	for _, record := range []interface{}{unknownOption{}, invalidDefault{}} {
+		if _, err := codec.NativeToBinary(record, []byte{}); err == nil {
+			tests.Info("Record: %#v", record)
+			tests.Failed("Should have failed to encode record with invalid tags")
+		}
+	}
+	tests.Passed("Should have failed to encode record with invalid tags")
+}
+
+func TestRecordCodec_RequiredAndUnknownFields(t *testing.T) {
+	type sent struct {
+		Age   int    `id:"1"`
+		Notes string `id:"3"`
+		Zip   uint32 `id:"4"`
+	}
+
+	type received struct {
+		Age  int    `id:"1,required"`
+		Name string `id:"2,required"`
+		Zip  uint32 `id:"4,required"`
+	}
+
+	var codec codecs.RecordCodec
+	encoded, err := codec.NativeToBinary(sent{Age: 20, Notes: "none", Zip: 2020}, []byte{})
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded record")
+	}
+	tests.Passed("Should have successfully encoded record")
+
+	var res received
+	err = codec.BinaryToNative(encoded, &res)
+
+	var fieldErr *voxa.FieldError
+	if !errors.As(err, &fieldErr) || !reflect.DeepEqual(fieldErr.Missing, []voxa.FieldID{2}) || fieldErr.Unknown != nil {
+		tests.Info("Received: %#v", err)
+		tests.Failed("Should have failed with missing required field ids")
+	}
+	tests.Passed("Should have failed with missing required field ids")
+
+	var strict struct {
+		Age int    `id:"1"`
+		Zip uint32 `id:"4"`
+	}
+
+	if err := codec.BinaryToNative(encoded, &strict); err != nil {
+		tests.FailedWithError(err, "Should have skipped unknown fields by default")
+	}
+	tests.Passed("Should have skipped unknown fields by default")
+
+	err = codecs.RecordCodec{DisallowUnknownFields: true}.BinaryToNative(encoded, &strict)
+	if !errors.As(err, &fieldErr) || !reflect.DeepEqual(fieldErr.Unknown, []voxa.FieldID{3}) || fieldErr.Missing != nil {
+		tests.Info("Received: %#v", err)
+		tests.Failed("Should have failed with unknown field ids")
+	}
+	tests.Passed("Should have failed with unknown field ids")
+
+	err = voxa.UnmarshalWithLimits(encoded, &strict, voxa.Limits{DisallowUnknownFields: true})
+	if !errors.As(err, &fieldErr) || !reflect.DeepEqual(fieldErr.Unknown, []voxa.FieldID{3}) {
+		tests.Info("Received: %#v", err)
+		tests.Failed("Should have failed with unknown field ids with limits")
+	}
+	tests.Passed("Should have failed with unknown field ids with limits")
+}
+
+func TestRecordCodec_Unknown(t *testing.T) {
+	type newer struct {
+		Age     int      `id:"1"`
+		Name    string   `id:"2"`
+		Aliases []string `id:"3"`
+		Home    *Address `id:"4"`
+	}
+
+	type older struct {
+		Age   int          `id:"1"`
+		Extra voxa.Unknown `id:"-"`
+	}
+
+	record := newer{Age: 20, Name: "bob", Aliases: []string{"rob"}, Home: &Address{Value: "Lane"}}
+
+	var codec codecs.RecordCodec
+	encoded, err := codec.NativeToBinary(record, []byte{})
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded record")
+	}
+	tests.Passed("Should have successfully encoded record")
+
+	relay := older{Extra: voxa.Unknown("stale")}
+	if err := (codecs.RecordCodec{DisallowUnknownFields: true}).BinaryToNative(encoded, &relay); err != nil {
+		tests.FailedWithError(err, "Should have successfully decoded record keeping unknown fields")
+	}
+	tests.Passed("Should have successfully decoded record keeping unknown fields")
+
+	relayed, err := codec.NativeToBinary(relay, []byte{})
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded record with unknown fields")
+	}
+	tests.Passed("Should have successfully encoded record with unknown fields")
+
+	if !bytes.Equal(relayed, encoded) {
+		tests.Info("Received: %#v", relayed)
+		tests.Info("Expected: %#v", encoded)
+		tests.Failed("Should have encoded unknown fields verbatim")
+	}
+	tests.Passed("Should have encoded unknown fields verbatim")
+
+	var res newer
+	if err := codec.BinaryToNative(relayed, &res); err != nil {
+		tests.FailedWithError(err, "Should have successfully decoded relayed record")
+	}
+
+	if !reflect.DeepEqual(res, record) {
+		tests.Info("Received: %#v", res)
+		tests.Info("Expected: %#v", record)
+		tests.Failed("Should have matching values between input and relayed record")
+	}
+	tests.Passed("Should have matching values between input and relayed record")
+}
+
+type Entity struct {
+	ID   uint32 `id:"1"`
+	Name string `id:"2"`
+}
+
+type Stamp struct {
+	Author string `id:"3"`
+}
+
+type unexportedEntity struct {
+	Kind string `id:"4"`
+}
+
+func TestRecordCodec_Embedded(t *testing.T) {
+	type record struct {
+		Entity
+		*Stamp
+		unexportedEntity
+
+		Name  string `id:"5"`
+		notes []string
+	}
+
+	value := record{
+		Entity:           Entity{ID: 20, Name: "hidden"},
+		Stamp:            &Stamp{Author: "bob"},
+		unexportedEntity: unexportedEntity{Kind: "person"},
+		Name:             "rob",
+		notes:            []string{"not encoded"},
+	}
+
+	type flat struct {
+		ID     uint32 `id:"1"`
+		Author string `id:"3"`
+		Kind   string `id:"4"`
+		Name   string `id:"5"`
+	}
+
+	var codec codecs.RecordCodec
+	encoded, err := codec.NativeToBinary(value, []byte{})
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded embedded structs")
+	}
+	tests.Passed("Should have successfully encoded embedded structs")
+
+	expected, err := codec.NativeToBinary(flat{ID: 20, Author: "bob", Kind: "person", Name: "rob"}, []byte{})
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded flat record")
+	}
+
+	if !bytes.Equal(encoded, expected) {
+		tests.Info("Received: %#v", encoded)
+		tests.Info("Expected: %#v", expected)
+		tests.Failed("Should have promoted fields of embedded structs")
+	}
+	tests.Passed("Should have promoted fields of embedded structs")
+
+	var res record
+	if err := codec.BinaryToNative(encoded, &res); err != nil {
+		tests.FailedWithError(err, "Should have successfully decoded embedded structs")
+	}
+	tests.Passed("Should have successfully decoded embedded structs")
+
+	value.Entity.Name, value.notes = "", nil
+	if !reflect.DeepEqual(res, value) {
+		tests.Info("Received: %#v", res)
+		tests.Info("Expected: %#v", value)
+		tests.Failed("Should have matching embedded structs between input and res")
+	}
+	tests.Passed("Should have matching embedded structs between input and res")
+
+	encoded, err = codec.NativeToBinary(record{Name: "rob"}, []byte{})
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded nil embedded pointer")
+	}
+
+	res = record{}
+	if err := codec.BinaryToNative(encoded, &res); err != nil || res.Stamp != nil {
+		tests.Info("Received: %#v", res)
+		tests.Failed("Should have left out fields of nil embedded pointer")
+	}
+	tests.Passed("Should have left out fields of nil embedded pointer")
+}
+
+func TestRecordCodec_Embedded_Invalid(t *testing.T) {
+	type conflicting struct {
+		Entity
+		Age int `id:"2"`
+	}
+
+	var codec codecs.RecordCodec
+	if _, err := codec.NativeToBinary(conflicting{}, []byte{}); !errors.Is(err, codecs.ErrTagMustBeUniqueToField) {
+		tests.Info("Received: %#v", err)
+		tests.Failed("Should have failed due to id of promoted field")
+	}
+	tests.Passed("Should have failed due to id of promoted field")
+
+	type unexported struct {
+		Age  int    `id:"1"`
+		name string `id:"2"`
+	}
+
+	if _, err := codec.NativeToBinary(unexported{name: "bob"}, []byte{}); err == nil {
+		tests.Failed("Should have failed due to tagged unexported field")
+	}
+	tests.Passed("Should have failed due to tagged unexported field")
+}
+
+func TestRecordCodec_WideFieldIDs(t *testing.T) {
+	type wide struct {
+		Age     int      `id:"1"`
+		Name    string   `id:"200"`
+		Address string   `id:"300"`
+		Notes   []string `id:"536870912"`
+	}
+
+	notes := make([]string, 300)
+	for i := range notes {
+		notes[i] = strconv.Itoa(i)
+	}
+
+	record := wide{Age: 20, Name: "bob", Address: "20. Classy Street", Notes: notes}
+
+	for _, codec := range []codecs.RecordCodec{{}, {Described: true}} {
+		encoded, err := codec.NativeToBinary(record, []byte{})
+		if err != nil {
+			tests.FailedWithError(err, "Should have successfully encoded wide field ids")
+		}
+		tests.Passed("Should have successfully encoded wide field ids")
+
+		var res wide
+		if err := codec.BinaryToNative(encoded, &res); err != nil {
+			tests.FailedWithError(err, "Should have successfully decoded wide field ids")
+		}
+		tests.Passed("Should have successfully decoded wide field ids")
+
+		if !reflect.DeepEqual(res, record) {
+			tests.Info("Received: %#v", res)
+			tests.Info("Expected: %#v", record)
+			tests.Failed("Should have matching wide field ids between input and res")
+		}
+		tests.Passed("Should have matching wide field ids between input and res")
+	}
+
+	encoded, err := codecs.RecordCodec{}.NativeToBinary(struct {
+		Age     int    `id:"1"`
+		Address string `id:"300"`
+	}{Age: 20, Address: "a"}, []byte{})
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded wide field ids")
+	}
+
+	expected := []byte{11, byte(voxa.Record), 0, 3, byte(voxa.SInt), 1, 40, 4, byte(voxa.Text), 0xac, 0x02, 'a'}
+	if !bytes.Equal(encoded, expected) {
+		tests.Info("Received: %#v", encoded)
+		tests.Info("Expected: %#v", expected)
+		tests.Failed("Should have encoded field ids as varints")
+	}
+	tests.Passed("Should have encoded field ids as varints")
+}
@@ -0,0 +1,135 @@
+package codecs
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/wirekit/voxa"
+)
+
+var (
+	// ErrNoDescribedRecord is returned when a Schema frame does not end with
+	// the record it describes.
+	ErrNoDescribedRecord = errors.New("schema has no described record")
+)
+
+// A described record is a Schema frame with format:
+//
+//	[Length VarInt][Schema][FieldID][Entry Frames...][Record Frame]
+//
+// where every entry frame holds the HeaderCodec encoding of a field of the
+// record: `[Length VarInt][FieldID VarInt][Atom][Name Bytes...]`.
+
+// readSchema returns the field names of provided Schema frame content keyed
+// by their FieldID, and the content of the record frame it describes.
+func readSchema(content []byte) (map[voxa.FieldID]string, []byte, error) {
+	_, _, entries, err := ReadHeader(content)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	names := map[voxa.FieldID]string{}
+	for len(entries) > 0 {
+		_, entry, rest, err := NextFrame(entries)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// the last frame is the described record.
+		if len(rest) == 0 {
+			if voxa.Atom(entry[0]) != voxa.Record {
+				return nil, nil, ErrNotRecord
+			}
+			return names, entry, nil
+		}
+
+		name, id, _, err := headerCodec.BinaryToField(entry)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		names[id] = name
+		entries = rest
+	}
+
+	return nil, nil, ErrNoDescribedRecord
+}
+
+// decodeDescribed decodes provided Schema frame content into dest, matching
+// the fields of the described record by name for structs and maps with
+// string keys, and by FieldID for all other maps.
+func (lc RecordCodec) decodeDescribed(d *decodeState, content []byte, dest reflect.Value) error {
+	names, record, err := readSchema(content)
+	if err != nil {
+		return err
+	}
+
+	switch dest.Kind() {
+	case reflect.Struct:
+		return lc.decodeStruct(d, record, dest, names)
+	case reflect.Map:
+		if dest.Type().Key().Kind() == reflect.String {
+			return lc.decodeNamedMap(d, record, dest, names)
+		}
+		return lc.decodeMap(d, record, dest)
+	case reflect.Interface:
+		if dest.NumMethod() == 0 {
+			named := reflect.ValueOf(map[string]interface{}{})
+			if err := lc.decodeNamedMap(d, record, named, names); err != nil {
+				return err
+			}
+
+			dest.Set(named)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("can not decode %s into %q", voxa.Schema, dest.Type())
+}
+
+// decodeNamedMap decodes the frames within provided record content into
+// provided map value with string keys, where each value is keyed by the
+// name of it's FieldID. Frames without a name are skipped.
+func (lc RecordCodec) decodeNamedMap(d *decodeState, content []byte, dest reflect.Value, names map[voxa.FieldID]string) error {
+	if err := d.enter(); err != nil {
+		return err
+	}
+	defer d.leave()
+
+	mapType := dest.Type()
+	if dest.IsNil() {
+		dest.Set(reflect.MakeMap(mapType))
+	}
+
+	_, _, dataFrame, err := ReadHeader(content)
+	if err != nil {
+		return err
+	}
+
+	for len(dataFrame) > 0 {
+		frame, subContent, rest, err := NextFrame(dataFrame)
+		if err != nil {
+			return d.fail(dataFrame, err)
+		}
+
+		_, id, _, err := ReadHeader(subContent)
+		if err != nil {
+			return d.fail(frame, err)
+		}
+
+		if name, ok := names[id]; ok {
+			value := reflect.New(mapType.Elem()).Elem()
+			if err := d.decodeValue(frame, subContent, value); err != nil {
+				return withPath(err, fmt.Sprintf("[%s]", name))
+			}
+
+			dest.SetMapIndex(reflect.ValueOf(name).Convert(mapType.Key()), value)
+		}
+
+		// Reduce current length of slice.
+		dataFrame = rest
+	}
+
+	return nil
+}
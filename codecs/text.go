@@ -20,21 +20,25 @@ var _ voxa.Codec = TextCodec{}
 type TextCodec struct{}
 
 func (TextCodec) BinaryToNative(b []byte) (interface{}, voxa.FieldID, error) {
-	if len(b) < 3 {
-		return nil, 0, errors.New("byte slice must be of length 2")
+	// an empty value has no data after the Atom and FieldID.
+	atom, id, data, err := ReadHeader(b)
+	if err != nil {
+		return nil, 0, err
 	}
 
-	id := voxa.FieldID(b[1])
-	if voxa.Atom(b[0]) != voxa.Text {
+	if atom != voxa.Text {
 		return nil, id, errors.New("byte slice must have supported type marker")
 	}
 
-	return string(b[2:]), id, nil
+	return string(data), id, nil
 }
 
 func (TextCodec) NativeToBinary(b interface{}, id voxa.FieldID, c []byte) ([]byte, error) {
 	if val, ok := b.(string); ok {
-		return append(append(c, byte(voxa.Text), byte(id)), val...), nil
+		return append(AppendHeader(c, voxa.Text, id), val...), nil
+	}
+	if val, ok := unnamed(b); ok {
+		return TextCodec{}.NativeToBinary(val, id, c)
 	}
 	return nil, errors.New("only string type supported")
 }
@@ -51,6 +55,9 @@ func (TextCodec) NativeToTextual(b interface{}, c []byte) ([]byte, error) {
 	if value, ok := b.(string); ok {
 		return append(c, strconv.Quote(value)...), nil
 	}
+	if val, ok := unnamed(b); ok {
+		return TextCodec{}.NativeToTextual(val, c)
+	}
 	return nil, errors.New("only string type supported")
 }
 
@@ -41,6 +41,25 @@ func TestTextCodec_BinaryToNative(t *testing.T) {
 	}
 }
 
+func TestTextCodec_BinaryToNative_Empty(t *testing.T) {
+	var codec codecs.TextCodec
+	encoded, err := codec.NativeToBinary("", 1, nil)
+	if err != nil {
+		tests.FailedWithError(err, "expected no error with encoding")
+	}
+
+	decoded, _, err := codec.BinaryToNative(encoded)
+	if err != nil {
+		tests.FailedWithError(err, "expected no error with decoding")
+	}
+
+	if decoded != "" {
+		tests.Info("Received: %+q", decoded)
+		tests.Failed("Should have received empty string")
+	}
+	tests.Passed("Should have received empty string")
+}
+
 func TestTextCodec_NativeToBinary(t *testing.T) {
 	var codec codecs.TextCodec
 	encoded, err := codec.NativeToBinary(textValue, 1, []byte{})
@@ -3,6 +3,7 @@ package codecs
 import (
 	"errors"
 	"strconv"
+	"sync"
 
 	"time"
 
@@ -11,41 +12,129 @@ import (
 
 var _ voxa.Codec = TimeCodec{}
 
+var (
+	// locations caches the *time.Location loaded for a location name.
+	locations sync.Map
+)
+
+// TimeCodec encodes time.Time values as a Time frame with format:
+//
+//	[Time][FieldID][Seconds ZigZag VarInt][Nanoseconds VarInt][Zone...]
+//
+// where seconds are counted from the Unix epoch. The zone is left out for
+// UTC times, else it holds `[Offset ZigZag VarInt][Location Name Bytes...]`,
+// where the offset is in seconds east of UTC. Times are decoded into their
+// named location when it is known and has the same offset, else into a
+// fixed zone of that name and offset.
 type TimeCodec struct{}
 
 func (TimeCodec) BinaryToNative(b []byte) (interface{}, voxa.FieldID, error) {
-	if len(b) < 3 {
+	atom, id, data, err := ReadHeader(b)
+	if err != nil || len(data) == 0 {
 		return nil, 0, errors.New("byte slice must be of length 2")
 	}
 
-	id := voxa.FieldID(b[1])
-	if voxa.Atom(b[0]) != voxa.Time {
+	if atom != voxa.Time {
 		return nil, id, errors.New("byte slice must have supported type marker")
 	}
 
-	tick, err := time.Parse(time.RFC3339, string(b[2:]))
+	// times were encoded as RFC3339 text before the binary form.
+	if tick, err := time.Parse(time.RFC3339Nano, string(data)); err == nil {
+		return tick, id, nil
+	}
+
+	tick, err := decodeTime(data)
 	return tick, id, err
 }
 
+func decodeTime(b []byte) (time.Time, error) {
+	secs, n := DecodeVarInt64(b)
+	if n == 0 {
+		return time.Time{}, ErrDecodeFailed
+	}
+	b = b[n:]
+
+	nanos, n := DecodeVarInt64(b)
+	if n == 0 || nanos >= uint64(time.Second) {
+		return time.Time{}, ErrDecodeFailed
+	}
+	b = b[n:]
+
+	tick := time.Unix(DecodeZigZag64(secs), int64(nanos)).UTC()
+	if len(b) == 0 {
+		return tick, nil
+	}
+
+	offset, n := DecodeVarInt64(b)
+	if n == 0 {
+		return time.Time{}, ErrDecodeFailed
+	}
+
+	return tick.In(timeLocation(string(b[n:]), int(DecodeZigZag64(offset)), tick)), nil
+}
+
+// timeLocation returns the location of provided name if it is known and
+// has provided offset at provided time, else a fixed zone.
+func timeLocation(name string, offset int, tick time.Time) *time.Location {
+	if name == "" {
+		return time.FixedZone(name, offset)
+	}
+
+	loc, ok := locations.Load(name)
+	if !ok {
+		loaded, err := time.LoadLocation(name)
+		if err != nil {
+			return time.FixedZone(name, offset)
+		}
+		loc, _ = locations.LoadOrStore(name, loaded)
+	}
+
+	if _, locOffset := tick.In(loc.(*time.Location)).Zone(); locOffset != offset {
+		return time.FixedZone(name, offset)
+	}
+	return loc.(*time.Location)
+}
+
 func (TimeCodec) NativeToBinary(b interface{}, id voxa.FieldID, c []byte) ([]byte, error) {
-	if val, ok := b.(time.Time); ok {
-		formatted := val.Format(time.RFC3339)
-		return append(append(c, byte(voxa.Time), byte(id)), formatted...), nil
+	val, ok := b.(time.Time)
+	if !ok {
+		return nil, errors.New("only time.Time type supported")
+	}
+
+	c = AppendHeader(c, voxa.Time, id)
+	c = append(c, EncodeVarInt64(EncodeZigZag64(val.Unix()))...)
+	c = append(c, EncodeVarInt64(uint64(val.Nanosecond()))...)
+	if val.Location() == time.UTC {
+		return c, nil
 	}
-	return nil, errors.New("only string type supported")
+
+	_, offset := val.Zone()
+	c = append(c, EncodeVarInt64(EncodeZigZag64(int64(offset)))...)
+	return append(c, val.Location().String()...), nil
 }
 
 func (TimeCodec) MarshalTextualToNative(_ []byte, _ interface{}) error {
 	return ErrNotSupported
 }
 
+// TextualToNative decodes a quoted RFC3339 time, with or without
+// nanoseconds.
 func (TimeCodec) TextualToNative(b []byte) (interface{}, error) {
-	return strconv.Unquote(string(b))
+	value, err := strconv.Unquote(string(b))
+	if err != nil {
+		return nil, err
+	}
+	return time.Parse(time.RFC3339Nano, value)
 }
 
+// NativeToTextual encodes a time.Time as a quoted RFC3339 time with
+// nanoseconds. Strings are quoted as they are.
 func (TimeCodec) NativeToTextual(b interface{}, c []byte) ([]byte, error) {
-	if value, ok := b.(string); ok {
+	switch value := b.(type) {
+	case time.Time:
+		return append(c, strconv.Quote(value.Format(time.RFC3339Nano))...), nil
+	case string:
 		return append(c, strconv.Quote(value)...), nil
 	}
-	return nil, errors.New("only string type supported")
+	return nil, errors.New("only time.Time and string types supported")
 }
@@ -0,0 +1,124 @@
+package codecs_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/influx6/faux/tests"
+	"github.com/wirekit/voxa"
+	"github.com/wirekit/voxa/codecs"
+)
+
+var (
+	timeValue       = time.Date(2018, 4, 1, 10, 30, 15, 123456789, time.UTC)
+	timeTextual     = []byte(`"2018-04-01T10:30:15.123456789Z"`)
+	goodEncodedTime = []byte{byte(voxa.Time), 1, 0xee, 0xd2, 0x85, 0xac, 0xb, 0x95, 0x9a, 0xef, 0x3a}
+)
+
+func TestTimeCodec_NativeToBinary(t *testing.T) {
+	var codec codecs.TimeCodec
+	encoded, err := codec.NativeToBinary(timeValue, 1, []byte{})
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded time value")
+	}
+
+	if !bytes.Equal(encoded, goodEncodedTime) {
+		tests.Info("Received: %#v", encoded)
+		tests.Info("Expected: %#v", goodEncodedTime)
+		tests.Failed("Should have matched encoded time value with expected")
+	}
+	tests.Passed("Should have matched encoded time value with expected")
+}
+
+func TestTimeCodec_BinaryToNative(t *testing.T) {
+	var codec codecs.TimeCodec
+	decoded, _, err := codec.BinaryToNative(goodEncodedTime)
+	if err != nil {
+		tests.FailedWithError(err, "expected no error with decoding")
+	}
+
+	if decoded != timeValue {
+		tests.Info("Received: %s", decoded)
+		tests.Info("Expected: %s", timeValue)
+		tests.Failed("Should have received expected decoded value")
+	}
+	tests.Passed("Should have received expected decoded value")
+}
+
+func TestTimeCodec_BinaryToNative_Zones(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		tests.FailedWithError(err, "Should have loaded location")
+	}
+
+	var codec codecs.TimeCodec
+	for _, zone := range []*time.Location{newYork, time.FixedZone("", -3600), time.FixedZone("CEST", 7200)} {
+		value := timeValue.In(zone)
+
+		encoded, err := codec.NativeToBinary(value, 1, []byte{})
+		if err != nil {
+			tests.FailedWithError(err, "Should have successfully encoded time value")
+		}
+
+		decoded, _, err := codec.BinaryToNative(encoded)
+		if err != nil {
+			tests.FailedWithError(err, "expected no error with decoding")
+		}
+
+		tick := decoded.(time.Time)
+		if !tick.Equal(value) || tick.Location().String() != zone.String() || tick.Format(time.RFC3339Nano) != value.Format(time.RFC3339Nano) {
+			tests.Info("Received: %s", tick)
+			tests.Info("Expected: %s", value)
+			tests.Failed("Should have decoded time value with it's zone")
+		}
+	}
+	tests.Passed("Should have decoded time value with it's zone")
+}
+
+func TestTimeCodec_BinaryToNative_RFC3339(t *testing.T) {
+	encoded := append([]byte{byte(voxa.Time), 1}, "2018-04-01T10:30:15Z"...)
+
+	var codec codecs.TimeCodec
+	decoded, _, err := codec.BinaryToNative(encoded)
+	if err != nil {
+		tests.FailedWithError(err, "expected no error with decoding")
+	}
+
+	if expected := timeValue.Truncate(time.Second); decoded != expected {
+		tests.Info("Received: %s", decoded)
+		tests.Info("Expected: %s", expected)
+		tests.Failed("Should have decoded time value encoded as text")
+	}
+	tests.Passed("Should have decoded time value encoded as text")
+}
+
+func TestTimeCodec_TextualToNative(t *testing.T) {
+	var codec codecs.TimeCodec
+	decoded, err := codec.TextualToNative(timeTextual)
+	if err != nil {
+		tests.FailedWithError(err, "expected no error with decoding")
+	}
+
+	if decoded != timeValue {
+		tests.Info("Received: %s", decoded)
+		tests.Info("Expected: %s", timeValue)
+		tests.Failed("Should have received expected decoded value")
+	}
+	tests.Passed("Should have received expected decoded value")
+}
+
+func TestTimeCodec_NativeToTextual(t *testing.T) {
+	var codec codecs.TimeCodec
+	encoded, err := codec.NativeToTextual(timeValue, []byte{})
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded time value")
+	}
+
+	if !bytes.Equal(encoded, timeTextual) {
+		tests.Info("Received: %+q", encoded)
+		tests.Info("Expected: %+q", timeTextual)
+		tests.Failed("Should have matched encoded time value with expected")
+	}
+	tests.Passed("Should have matched encoded time value with expected")
+}
@@ -0,0 +1,120 @@
+package codecs
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+
+	"github.com/wirekit/voxa"
+)
+
+// Values of a type registered with voxa.RegisterType which are held by an
+// interface are encoded as a Union frame with format:
+//
+//	[Union][FieldID VarInt][TypeID VarInt][Value Frame]
+//
+// where the value frame is the complete frame of the value, marked with a
+// FieldID of 0.
+
+// errors ...
+var (
+	// ErrMalformedUnion is returned when a Union frame does not hold a
+	// TypeID followed by a single frame.
+	ErrMalformedUnion = errors.New("union must hold a type id followed by a single frame")
+
+	// ErrUnknownTypeID is returned when the TypeID of a Union frame is not
+	// registered with voxa.RegisterType.
+	ErrUnknownTypeID = errors.New("type id is not registered")
+
+	// ErrUnionType is returned when the registered type of a Union frame
+	// does not implement the interface it is decoded into.
+	ErrUnionType = errors.New("union type does not implement interface")
+)
+
+// ReadUnion returns the TypeID and value frame of provided Union frame
+// content.
+func ReadUnion(content []byte) (voxa.TypeID, []byte, error) {
+	_, _, data, err := ReadHeader(content)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	typeID, n := DecodeVarInt64(data)
+	if n == 0 || typeID > math.MaxUint32 {
+		return 0, nil, ErrMalformedUnion
+	}
+
+	frame, _, rest, err := NextFrame(data[n:])
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if len(rest) != 0 {
+		return 0, nil, ErrMalformedUnion
+	}
+	return voxa.TypeID(typeID), frame, nil
+}
+
+// encodeUnion encodes provided value, whose type is registered with
+// provided TypeID, as a Union frame.
+func encodeUnion(v reflect.Value, typeID voxa.TypeID, id voxa.FieldID, c []byte) ([]byte, error) {
+	encode := encoderFor(v.Type())
+	if encode == nil {
+		return c, ErrSkipErr
+	}
+
+	c, start := ReserveFrame(c)
+	c = appendVarInt(AppendHeader(c, voxa.Union, id), uint64(typeID))
+
+	c, err := encode(v, 0, c)
+	if err != nil {
+		return c[:start], err
+	}
+	return CloseFrame(c, start), nil
+}
+
+// decodeUnion decodes provided Union frame content into dest. Interfaces
+// are set to a new value of the registered type, while all other values
+// decode the value frame as they would without the Union, as do empty
+// interfaces of a generic decode when the type is not registered.
+func (d *decodeState) decodeUnion(content []byte, dest reflect.Value) error {
+	if err := d.enter(); err != nil {
+		return err
+	}
+	defer d.leave()
+
+	typeID, frame, err := ReadUnion(content)
+	if err != nil {
+		return err
+	}
+
+	_, valueContent, _, err := NextFrame(frame)
+	if err != nil {
+		return err
+	}
+
+	if dest.Kind() != reflect.Interface {
+		return d.decodeValue(frame, valueContent, dest)
+	}
+
+	t, ok := voxa.TypeByID(typeID)
+	if !ok && d.generic && dest.NumMethod() == 0 {
+		return d.decodeValue(frame, valueContent, dest)
+	}
+	if !ok {
+		return fmt.Errorf("type id %d: %w", typeID, ErrUnknownTypeID)
+	}
+
+	if !t.AssignableTo(dest.Type()) {
+		return fmt.Errorf("type id %d is %q, not %q: %w", typeID, t, dest.Type(), ErrUnionType)
+	}
+
+	value := reflect.New(t).Elem()
+	if err := d.decodeValue(frame, valueContent, value); err != nil {
+		return err
+	}
+
+	dest.Set(value)
+	return nil
+}
@@ -0,0 +1,133 @@
+package codecs_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/influx6/faux/tests"
+	"github.com/wirekit/voxa"
+	"github.com/wirekit/voxa/codecs"
+)
+
+type shape interface {
+	Area() float64
+}
+
+type circle struct {
+	Radius float64 `id:"1"`
+}
+
+func (c circle) Area() float64 { return 3 * c.Radius * c.Radius }
+
+type square struct {
+	Side float64 `id:"1"`
+}
+
+func (s *square) Area() float64 { return s.Side * s.Side }
+
+type caption string
+
+func init() {
+	voxa.RegisterType(1, circle{})
+	voxa.RegisterType(2, &square{})
+	voxa.RegisterType(3, caption(""))
+}
+
+type drawing struct {
+	Main   shape                  `id:"1"`
+	Shapes []shape                `id:"2"`
+	Notes  []interface{}          `id:"3"`
+	Named  map[string]interface{} `id:"4"`
+	Empty  shape                  `id:"5"`
+}
+
+func TestUnion(t *testing.T) {
+	record := drawing{
+		Main:   circle{Radius: 2},
+		Shapes: []shape{&square{Side: 3}, circle{Radius: 1}, nil},
+		Notes:  []interface{}{caption("origin"), circle{Radius: 4}, "unregistered", 7},
+		Named:  map[string]interface{}{"corner": &square{Side: 1}},
+	}
+
+	encoded, err := voxa.Marshal(record)
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded interface values")
+	}
+	tests.Passed("Should have successfully encoded interface values")
+
+	// [Length][Record][FieldID][Length][Union][FieldID][TypeID][Value Frame...]
+	if voxa.Atom(encoded[4]) != voxa.Union || encoded[6] != 1 {
+		tests.Info("Received: %#v", encoded)
+		tests.Failed("Should have encoded registered type as Union frame")
+	}
+	tests.Passed("Should have encoded registered type as Union frame")
+
+	var res drawing
+	if err := voxa.Unmarshal(encoded, &res); err != nil {
+		tests.FailedWithError(err, "Should have successfully decoded interface values")
+	}
+	tests.Passed("Should have successfully decoded interface values")
+
+	if !reflect.DeepEqual(res, record) {
+		tests.Info("Received: %#v", res)
+		tests.Info("Expected: %#v", record)
+		tests.Failed("Should have decoded interface values into their registered types")
+	}
+	tests.Passed("Should have decoded interface values into their registered types")
+
+	var concrete struct {
+		Main circle `id:"1"`
+	}
+
+	if err := voxa.Unmarshal(encoded, &concrete); err != nil || concrete.Main.Radius != 2 {
+		tests.Info("Received: %#v", err)
+		tests.Failed("Should have decoded union value into concrete type")
+	}
+	tests.Passed("Should have decoded union value into concrete type")
+}
+
+func TestUnion_Invalid(t *testing.T) {
+	mislabeled, err := voxa.Marshal(struct {
+		Main interface{} `id:"1"`
+	}{Main: caption("circle")})
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded interface value")
+	}
+
+	var res drawing
+	if err := voxa.Unmarshal(mislabeled, &res); !errors.Is(err, codecs.ErrUnionType) {
+		tests.Info("Received: %#v", err)
+		tests.Failed("Should have failed to decode type not implementing interface")
+	}
+	tests.Passed("Should have failed to decode type not implementing interface")
+
+	c, start := codecs.ReserveFrame(nil)
+	c = append(codecs.AppendHeader(c, voxa.Union, 0), 99)
+	c, err = codecs.AppendFrame(codecs.TextCodec{}, "lost", 0, c)
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded union value")
+	}
+	unknown := codecs.CloseFrame(c, start)
+
+	var value interface{}
+	if err := voxa.Unmarshal(unknown, &value); !errors.Is(err, codecs.ErrUnknownTypeID) {
+		tests.Info("Received: %#v", err)
+		tests.Failed("Should have failed to decode unregistered type id")
+	}
+	tests.Passed("Should have failed to decode unregistered type id")
+
+	var text string
+	if err := voxa.Unmarshal(unknown, &text); err != nil || text != "lost" {
+		tests.Info("Received: %#v", err)
+		tests.Failed("Should have decoded union value into concrete type")
+	}
+	tests.Passed("Should have decoded union value into concrete type")
+
+	generic, err := voxa.DecodeAny(unknown)
+	if err != nil || generic != "lost" {
+		tests.Info("Received: %#v %#v", generic, err)
+		tests.Failed("Should have generically decoded unregistered type id")
+	}
+	tests.Passed("Should have generically decoded unregistered type id")
+}
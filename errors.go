@@ -0,0 +1,100 @@
+package voxa
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// DecodeError is returned when a frame fails to decode, locating the frame
+// within the decoded data and the value it was decoded into.
+type DecodeError struct {
+	// Offset is the position of the failing frame within the decoded data.
+	Offset int64
+
+	// Path is the path of the value the frame was decoded into, such as
+	// `OtherNames[3].Value`, which is empty for the decoded value itself.
+	Path string
+
+	// Expected is the Atom the value required, which is Invalid when any
+	// Atom was acceptable, and Actual the Atom of the failing frame.
+	Expected Atom
+	Actual   Atom
+
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	msg := "voxa: failed to decode"
+	if e.Path != "" {
+		msg += " " + e.Path
+	}
+
+	msg += fmt.Sprintf(" at offset %d", e.Offset)
+	if e.Expected != Invalid {
+		msg += fmt.Sprintf(", expected %s but got %s", e.Expected, e.Actual)
+	}
+
+	return msg + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// FieldError is returned when a record decoded into a struct is missing
+// fields with the required option, or holds fields without a matching struct
+// field while Limits.DisallowUnknownFields is set.
+type FieldError struct {
+	// Missing holds the FieldID of every missing required field.
+	Missing []FieldID
+
+	// Unknown holds the FieldID of every field without a matching struct
+	// field.
+	Unknown []FieldID
+}
+
+func (e *FieldError) Error() string {
+	var msgs []string
+	if len(e.Missing) != 0 {
+		msgs = append(msgs, fmt.Sprintf("missing required field ids %v", e.Missing))
+	}
+	if len(e.Unknown) != 0 {
+		msgs = append(msgs, fmt.Sprintf("unknown field ids %v", e.Unknown))
+	}
+	return strings.Join(msgs, " and ")
+}
+
+// EncodeError is returned when a value fails to encode, locating the value
+// within the encoded value.
+type EncodeError struct {
+	// Path is the path of the failing value, such as `OtherNames[3].Value`,
+	// which is empty for the encoded value itself.
+	Path string
+
+	// Type is the type of the failing value.
+	Type reflect.Type
+
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *EncodeError) Error() string {
+	msg := "voxa: failed to encode"
+	if e.Path != "" {
+		msg += " " + e.Path
+	}
+
+	if e.Type != nil {
+		msg += fmt.Sprintf(" of type %q", e.Type)
+	}
+
+	return msg + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *EncodeError) Unwrap() error {
+	return e.Err
+}
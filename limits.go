@@ -0,0 +1,97 @@
+package voxa
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrLimitExceeded is wrapped by every LimitError, hence
+// errors.Is(err, ErrLimitExceeded) reports whether decoding failed due to
+// a Limits value.
+var ErrLimitExceeded = errors.New("decode limit exceeded")
+
+// Limits holds the limits enforced while decoding, which protect decoders of
+// untrusted payloads from allocating unbounded memory or recursing without
+// end. Zero numeric fields are replaced by the value of the package level
+// variable of the same name.
+type Limits struct {
+	// MaxBlockCount is the maximum number of items of a list or entries of
+	// a map.
+	MaxBlockCount int64
+
+	// MaxBlockSize is the maximum number of bytes of a single frame.
+	MaxBlockSize int64
+
+	// MaxIBU8Count, MaxIBU16Count, MaxIBU32Count, MaxIBU64Count and
+	// MaxICU128Count are the maximum number of items of a slice whose
+	// elements are 1, 2, 4, 8 and 16 bytes wide.
+	MaxIBU8Count   int64
+	MaxIBU16Count  int64
+	MaxIBU32Count  int64
+	MaxIBU64Count  int64
+	MaxICU128Count int64
+
+	// MaxDepth is the maximum nesting depth of records, lists and maps.
+	MaxDepth int
+
+	// DisallowUnknownFields fails decoding a record into a struct with a
+	// *FieldError when it holds fields without a matching struct field,
+	// which are skipped otherwise. Types implementing Unmarshaler decode
+	// their own records, hence are only affected by it when they implement
+	// LimitedUnmarshaler.
+	DisallowUnknownFields bool
+}
+
+// DefaultLimits returns the Limits set by the package level variables.
+func DefaultLimits() Limits {
+	return Limits{}.withDefaults()
+}
+
+// withDefaults returns a copy of l whose zero fields are replaced by the
+// package level variables.
+func (l Limits) withDefaults() Limits {
+	if l.MaxBlockCount == 0 {
+		l.MaxBlockCount = MaxBlockCount
+	}
+	if l.MaxBlockSize == 0 {
+		l.MaxBlockSize = MaxBlockSize
+	}
+	if l.MaxIBU8Count == 0 {
+		l.MaxIBU8Count = MaxIBU8Count
+	}
+	if l.MaxIBU16Count == 0 {
+		l.MaxIBU16Count = MaxIBU16Count
+	}
+	if l.MaxIBU32Count == 0 {
+		l.MaxIBU32Count = MaxIBU32Count
+	}
+	if l.MaxIBU64Count == 0 {
+		l.MaxIBU64Count = MaxIBU64Count
+	}
+	if l.MaxICU128Count == 0 {
+		l.MaxICU128Count = MaxICU128Count
+	}
+	if l.MaxDepth == 0 {
+		l.MaxDepth = MaxDepth
+	}
+	return l
+}
+
+// LimitError is returned when decoding a payload exceeds one of the Limits.
+type LimitError struct {
+	// Limit is the name of the exceeded Limits field.
+	Limit string
+
+	// Value is the value found in the payload and Max it's limit.
+	Value int64
+	Max   int64
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("voxa: %d exceeds %s of %d", e.Value, e.Limit, e.Max)
+}
+
+// Unwrap returns ErrLimitExceeded.
+func (e *LimitError) Unwrap() error {
+	return ErrLimitExceeded
+}
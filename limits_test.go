@@ -0,0 +1,166 @@
+package voxa_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/influx6/faux/tests"
+	"github.com/wirekit/voxa"
+)
+
+type node struct {
+	Value    int    `id:"1"`
+	Children []node `id:"2"`
+}
+
+func nest(depth int) node {
+	root := node{Value: depth}
+	if depth > 0 {
+		root.Children = []node{nest(depth - 1)}
+	}
+	return root
+}
+
+func expectLimit(err error, limit string) {
+	var limitErr *voxa.LimitError
+	if !errors.As(err, &limitErr) || limitErr.Limit != limit {
+		tests.Info("Received: %+q", err)
+		tests.Failed("Should have failed with %s limit error", limit)
+	}
+
+	if !errors.Is(err, voxa.ErrLimitExceeded) {
+		tests.Failed("Should have wrapped ErrLimitExceeded")
+	}
+	tests.Passed("Should have failed with %s limit error", limit)
+}
+
+func TestUnmarshalWithLimits_MaxBlockCount(t *testing.T) {
+	encoded, err := voxa.Marshal([]string{"a", "b", "c", "d"})
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded list")
+	}
+	tests.Passed("Should have successfully encoded list")
+
+	var res []string
+	expectLimit(voxa.UnmarshalWithLimits(encoded, &res, voxa.Limits{MaxBlockCount: 3}), "MaxBlockCount")
+
+	if err := voxa.UnmarshalWithLimits(encoded, &res, voxa.Limits{MaxBlockCount: 4}); err != nil {
+		tests.FailedWithError(err, "Should have successfully decoded list within limit")
+	}
+	tests.Passed("Should have successfully decoded list within limit")
+}
+
+func TestUnmarshalWithLimits_MaxMapCount(t *testing.T) {
+	encoded, err := voxa.Marshal(map[string]int{"a": 1, "b": 2, "c": 3})
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded map")
+	}
+	tests.Passed("Should have successfully encoded map")
+
+	var res map[string]int
+	expectLimit(voxa.UnmarshalWithLimits(encoded, &res, voxa.Limits{MaxBlockCount: 2}), "MaxBlockCount")
+}
+
+func TestUnmarshalWithLimits_ElementWidth(t *testing.T) {
+	encoded, err := voxa.Marshal([]uint16{1, 2, 3, 4, 5})
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded list")
+	}
+	tests.Passed("Should have successfully encoded list")
+
+	var res []uint16
+	expectLimit(voxa.UnmarshalWithLimits(encoded, &res, voxa.Limits{MaxIBU16Count: 4}), "MaxIBU16Count")
+
+	// the limit only applies to elements of it's width.
+	var wide []uint64
+	if err := voxa.UnmarshalWithLimits(encoded, &wide, voxa.Limits{MaxIBU16Count: 4}); err != nil {
+		tests.FailedWithError(err, "Should have successfully decoded list of other width")
+	}
+	tests.Passed("Should have successfully decoded list of other width")
+}
+
+func TestUnmarshalWithLimits_MaxBlockSize(t *testing.T) {
+	encoded, err := voxa.Marshal(user{Name: "bob", Age: 20, Interests: []string{"daydreaming", "hacking"}})
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded record")
+	}
+	tests.Passed("Should have successfully encoded record")
+
+	var res user
+	expectLimit(voxa.UnmarshalWithLimits(encoded, &res, voxa.Limits{MaxBlockSize: 10}), "MaxBlockSize")
+}
+
+func TestUnmarshalWithLimits_MaxDepth(t *testing.T) {
+	encoded, err := voxa.Marshal(nest(10))
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded nested records")
+	}
+	tests.Passed("Should have successfully encoded nested records")
+
+	var res node
+	expectLimit(voxa.UnmarshalWithLimits(encoded, &res, voxa.Limits{MaxDepth: 8}), "MaxDepth")
+
+	var generic interface{}
+	expectLimit(voxa.UnmarshalWithLimits(encoded, &generic, voxa.Limits{MaxDepth: 8}), "MaxDepth")
+
+	if err := voxa.Unmarshal(encoded, &res); err != nil {
+		tests.FailedWithError(err, "Should have successfully decoded nested records with default limits")
+	}
+	tests.Passed("Should have successfully decoded nested records with default limits")
+}
+
+func TestUnmarshalWithLimits_MaxDepth_Path(t *testing.T) {
+	encoded, err := voxa.Marshal(nest(3000))
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded nested records")
+	}
+	tests.Passed("Should have successfully encoded nested records")
+
+	var res node
+	err = voxa.UnmarshalWithLimits(encoded, &res, voxa.Limits{MaxDepth: 6000})
+	expectLimit(err, "MaxDepth")
+
+	var decodeErr *voxa.DecodeError
+	if !errors.As(err, &decodeErr) || len(decodeErr.Path) > 300 || !strings.Contains(decodeErr.Path, "...") {
+		tests.Info("Received: %d bytes of path", len(decodeErr.Path))
+		tests.Failed("Should have collapsed the path of deeply nested error")
+	}
+	tests.Passed("Should have collapsed the path of deeply nested error")
+
+	if !strings.HasPrefix(decodeErr.Path, "Children[0].Children[0]") || !strings.HasSuffix(decodeErr.Path, "Children[0]") {
+		tests.Info("Received: %+q", decodeErr.Path)
+		tests.Failed("Should have kept the outermost and innermost segments of the path")
+	}
+	tests.Passed("Should have kept the outermost and innermost segments of the path")
+}
+
+func TestDecoder_SetLimits(t *testing.T) {
+	var buf bytes.Buffer
+	encoder := voxa.NewEncoder(&buf)
+	if err := encoder.Encode(user{Name: "bob", Age: 20, Interests: []string{"daydreaming", "hacking"}}); err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded record into stream")
+	}
+	tests.Passed("Should have successfully encoded record into stream")
+
+	decoder := voxa.NewDecoder(&buf)
+	decoder.SetLimits(voxa.Limits{MaxBlockSize: 10})
+
+	var res user
+	expectLimit(decoder.Decode(&res), "MaxBlockSize")
+}
+
+func TestDecoder_SetLimits_MaxDepth(t *testing.T) {
+	var buf bytes.Buffer
+	if err := voxa.NewEncoder(&buf).Encode(nest(10)); err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded nested records into stream")
+	}
+	tests.Passed("Should have successfully encoded nested records into stream")
+
+	decoder := voxa.NewDecoder(&buf)
+	decoder.SetLimits(voxa.Limits{MaxDepth: 4})
+
+	var res node
+	expectLimit(decoder.Decode(&res), "MaxDepth")
+}
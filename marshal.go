@@ -0,0 +1,88 @@
+package voxa
+
+import (
+	"errors"
+)
+
+// ErrNoEngine is returned by the package level functions when no Engine
+// has been registered. Importing the codecs package registers the default
+// Engine.
+var ErrNoEngine = errors.New("no voxa engine registered, import github.com/wirekit/voxa/codecs")
+
+// Engine defines a type which exposes methods to encode any supported
+// native value into a voxa frame and decode a voxa frame back into a
+// native value, routing each value to the codec that matches it.
+type Engine interface {
+	// Marshal encodes provided value into a voxa frame which is appended
+	// to provided byte slice, returning provided byte slice with new length.
+	Marshal(interface{}, []byte) ([]byte, error)
+
+	// Unmarshal decodes the voxa frame in provided byte slice into the
+	// value pointed to by provided pointer, enforcing provided Limits.
+	Unmarshal([]byte, interface{}, Limits) error
+}
+
+// AnyDecoder is implemented by Engines which decode a voxa frame without a
+// destination type, as used by DecodeAny.
+type AnyDecoder interface {
+	// DecodeAny decodes the voxa frame in provided byte slice into its
+	// generic value, enforcing provided Limits.
+	DecodeAny([]byte, Limits) (interface{}, error)
+}
+
+var engine Engine
+
+// RegisterEngine sets the Engine used by Marshal and Unmarshal. It is
+// meant to be called during package initialization and is not safe for
+// concurrent use with Marshal or Unmarshal.
+func RegisterEngine(e Engine) {
+	engine = e
+}
+
+// Marshal returns the voxa encoding of v, where v is a struct, map, slice,
+// array or scalar value or a pointer to one.
+func Marshal(v interface{}) ([]byte, error) {
+	if engine == nil {
+		return nil, ErrNoEngine
+	}
+	return engine.Marshal(v, nil)
+}
+
+// Unmarshal decodes the voxa encoded data into the value pointed to by v,
+// which must be a non-nil pointer. It enforces the DefaultLimits.
+func Unmarshal(data []byte, v interface{}) error {
+	return UnmarshalWithLimits(data, v, Limits{})
+}
+
+// UnmarshalWithLimits decodes the voxa encoded data into the value pointed
+// to by v, returning a *LimitError when the data exceeds provided limits.
+// Zero fields of limits are replaced by the DefaultLimits.
+func UnmarshalWithLimits(data []byte, v interface{}, limits Limits) error {
+	if engine == nil {
+		return ErrNoEngine
+	}
+	return engine.Unmarshal(data, v, limits.withDefaults())
+}
+
+// DecodeAny decodes the voxa encoded data without knowing its Go type.
+// Records decode into a map[FieldID]interface{}, or into a
+// map[string]interface{} keyed by field name when they are described by a
+// schema, maps into a map[interface{}]interface{}, lists and packed values
+// into a []interface{} and scalars into their Go value. Unions decode into
+// their registered type, or into the generic value they hold when their
+// TypeID is not registered. It enforces the DefaultLimits.
+func DecodeAny(data []byte) (interface{}, error) {
+	if engine == nil {
+		return nil, ErrNoEngine
+	}
+
+	if decoder, ok := engine.(AnyDecoder); ok {
+		return decoder.DecodeAny(data, Limits{}.withDefaults())
+	}
+
+	var v interface{}
+	if err := engine.Unmarshal(data, &v, Limits{}.withDefaults()); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
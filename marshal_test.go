@@ -0,0 +1,203 @@
+package voxa_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/influx6/faux/tests"
+	"github.com/wirekit/voxa"
+	"github.com/wirekit/voxa/codecs"
+)
+
+type user struct {
+	Name      string   `id:"1"`
+	Age       int      `id:"2"`
+	Interests []string `id:"3"`
+}
+
+func TestMarshal_Struct(t *testing.T) {
+	record := user{Name: "bob", Age: 20, Interests: []string{"daydreaming", "hacking"}}
+
+	encoded, err := voxa.Marshal(record)
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully marshalled struct")
+	}
+	tests.Passed("Should have successfully marshalled struct")
+
+	var res user
+	if err := voxa.Unmarshal(encoded, &res); err != nil {
+		tests.FailedWithError(err, "Should have successfully unmarshalled struct")
+	}
+	tests.Passed("Should have successfully unmarshalled struct")
+
+	if !reflect.DeepEqual(res, record) {
+		tests.Info("Received: %#v", res)
+		tests.Info("Expected: %#v", record)
+		tests.Failed("Should have matching elements between input and res")
+	}
+	tests.Passed("Should have matching elements between input and res")
+}
+
+func TestMarshal_Slice(t *testing.T) {
+	contents := []user{
+		{Name: "bob", Age: 20, Interests: []string{"hacking"}},
+		{Name: "alice", Age: 32, Interests: []string{"running", "reading"}},
+	}
+
+	encoded, err := voxa.Marshal(&contents)
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully marshalled slice")
+	}
+	tests.Passed("Should have successfully marshalled slice")
+
+	var res []user
+	if err := voxa.Unmarshal(encoded, &res); err != nil {
+		tests.FailedWithError(err, "Should have successfully unmarshalled slice")
+	}
+	tests.Passed("Should have successfully unmarshalled slice")
+
+	if !reflect.DeepEqual(res, contents) {
+		tests.Info("Received: %#v", res)
+		tests.Info("Expected: %#v", contents)
+		tests.Failed("Should have matching elements between input and res")
+	}
+	tests.Passed("Should have matching elements between input and res")
+}
+
+func TestMarshal_Scalars(t *testing.T) {
+	encoded, err := voxa.Marshal("we going to reck some stages")
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully marshalled string")
+	}
+
+	var text string
+	if err := voxa.Unmarshal(encoded, &text); err != nil {
+		tests.FailedWithError(err, "Should have successfully unmarshalled string")
+	}
+
+	if text != "we going to reck some stages" {
+		tests.Failed("Should have matching string between input and res")
+	}
+	tests.Passed("Should have matching string between input and res")
+
+	encoded, err = voxa.Marshal(uint16(4096))
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully marshalled uint16")
+	}
+
+	var number uint64
+	if err := voxa.Unmarshal(encoded, &number); err != nil {
+		tests.FailedWithError(err, "Should have successfully unmarshalled uint16 into uint64")
+	}
+
+	if number != 4096 {
+		tests.Failed("Should have matching number between input and res")
+	}
+	tests.Passed("Should have matching number between input and res")
+}
+
+func TestUnmarshal_RequiresPointer(t *testing.T) {
+	encoded, err := voxa.Marshal(true)
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully marshalled bool")
+	}
+
+	var res bool
+	if err := voxa.Unmarshal(encoded, res); err == nil {
+		tests.Failed("Should have failed to unmarshal into non-pointer")
+	}
+	tests.Passed("Should have failed to unmarshal into non-pointer")
+}
+
+func TestUnmarshal_TrailingData(t *testing.T) {
+	encoded, err := voxa.Marshal(user{Name: "bob", Age: 20})
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully marshalled struct")
+	}
+
+	trailing := append(encoded, 0xff, 0xff)
+
+	var res user
+	if err := voxa.Unmarshal(trailing, &res); !errors.Is(err, codecs.ErrTrailingData) {
+		tests.FailedWithError(err, "Should have failed to unmarshal data trailing the frame")
+	}
+	tests.Passed("Should have failed to unmarshal data trailing the frame")
+
+	if _, err := voxa.DecodeAny(trailing); !errors.Is(err, codecs.ErrTrailingData) {
+		tests.FailedWithError(err, "Should have failed to decode data trailing the frame")
+	}
+	tests.Passed("Should have failed to decode data trailing the frame")
+}
+
+func TestDecodeAny(t *testing.T) {
+	record := user{Name: "bob", Age: 20, Interests: []string{"daydreaming", "hacking"}}
+
+	encoded, err := voxa.Marshal(record)
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully marshalled struct")
+	}
+
+	res, err := voxa.DecodeAny(encoded)
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully decoded struct")
+	}
+	tests.Passed("Should have successfully decoded struct")
+
+	expected := map[voxa.FieldID]interface{}{
+		1: "bob",
+		2: 20,
+		3: []interface{}{"daydreaming", "hacking"},
+	}
+	if !reflect.DeepEqual(res, expected) {
+		tests.Info("Received: %#v", res)
+		tests.Info("Expected: %#v", expected)
+		tests.Failed("Should have decoded record keyed by field id")
+	}
+	tests.Passed("Should have decoded record keyed by field id")
+
+	described, err := codecs.RecordCodec{Described: true}.NativeToBinary(record, nil)
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded described struct")
+	}
+
+	res, err = voxa.DecodeAny(described)
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully decoded described struct")
+	}
+
+	named := map[string]interface{}{
+		"Name":      "bob",
+		"Age":       20,
+		"Interests": []interface{}{"daydreaming", "hacking"},
+	}
+	if !reflect.DeepEqual(res, named) {
+		tests.Info("Received: %#v", res)
+		tests.Info("Expected: %#v", named)
+		tests.Failed("Should have decoded described record keyed by name")
+	}
+	tests.Passed("Should have decoded described record keyed by name")
+
+	encoded, err = voxa.Marshal(map[string][]float64{"scores": {1.5, 2}})
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully marshalled map")
+	}
+
+	res, err = voxa.DecodeAny(encoded)
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully decoded map")
+	}
+
+	scores := map[interface{}]interface{}{"scores": []interface{}{1.5, float64(2)}}
+	if !reflect.DeepEqual(res, scores) {
+		tests.Info("Received: %#v", res)
+		tests.Info("Expected: %#v", scores)
+		tests.Failed("Should have decoded map with packed values")
+	}
+	tests.Passed("Should have decoded map with packed values")
+
+	if _, err := voxa.DecodeAny(encoded[:len(encoded)-1]); err == nil {
+		tests.Failed("Should have failed to decode truncated data")
+	}
+	tests.Passed("Should have failed to decode truncated data")
+}
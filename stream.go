@@ -0,0 +1,209 @@
+package voxa
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+const (
+	// minReadSize sets the minimum amount of bytes a Decoder reads from
+	// it's underline reader during each refill.
+	minReadSize = 512
+
+	// maxVarIntLen is the maximum number of bytes used by a varint
+	// encoded uint64.
+	maxVarIntLen = 10
+
+	// maxEmptyReads is the number of reads returning no bytes and no error
+	// after which a Decoder fails with io.ErrNoProgress.
+	maxEmptyReads = 100
+)
+
+var (
+	// ErrInvalidFrameSize is returned when a frame read from a stream
+	// contains an invalid varint length prefix.
+	ErrInvalidFrameSize = errors.New("invalid frame length prefix")
+)
+
+// Encoder writes voxa frames into an underline io.Writer.
+type Encoder struct {
+	w   io.Writer
+	buf []byte
+}
+
+// NewEncoder returns a new Encoder which writes into w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w}
+}
+
+// Encode writes the voxa encoding of v into the underline writer. Each
+// encoded value is a length prefixed frame, hence values written one after
+// the other can be read back in order by a Decoder.
+func (e *Encoder) Encode(v interface{}) error {
+	if engine == nil {
+		return ErrNoEngine
+	}
+
+	encoded, err := engine.Marshal(v, e.buf[:0])
+	if err != nil {
+		return err
+	}
+
+	e.buf = encoded
+	_, err = e.w.Write(encoded)
+	return err
+}
+
+// Decoder reads voxa frames from an underline io.Reader, buffering reads
+// and refilling it's buffer incrementally until a complete frame is
+// available.
+type Decoder struct {
+	r               io.Reader
+	buf             []byte
+	off             int
+	err             error
+	limits          Limits
+	disallowUnknown bool
+}
+
+// NewDecoder returns a new Decoder which reads from r, enforcing the
+// DefaultLimits.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: r, limits: DefaultLimits()}
+}
+
+// SetLimits sets the Limits enforced by the Decoder, where zero fields are
+// replaced by the DefaultLimits. A Decoder whose DisallowUnknownFields was
+// called keeps disallowing unknown fields.
+func (d *Decoder) SetLimits(limits Limits) {
+	d.limits = limits.withDefaults()
+}
+
+// DisallowUnknownFields causes the Decoder to fail decoding records which
+// hold fields without a matching struct field, as Limits.DisallowUnknownFields.
+func (d *Decoder) DisallowUnknownFields() {
+	d.disallowUnknown = true
+}
+
+// More reports whether there is another frame available to be decoded.
+func (d *Decoder) More() bool {
+	return d.fill(1) == nil
+}
+
+// Decode reads the next frame from the underline reader and decodes it
+// into the value pointed to by v. It returns io.EOF when there are no
+// more frames to be read.
+func (d *Decoder) Decode(v interface{}) error {
+	if engine == nil {
+		return ErrNoEngine
+	}
+
+	frame, err := d.readFrame()
+	if err != nil {
+		return err
+	}
+
+	limits := d.limits
+	if d.disallowUnknown {
+		limits.DisallowUnknownFields = true
+	}
+	return engine.Unmarshal(frame, v, limits)
+}
+
+// readFrame returns the next complete frame, including it's length prefix.
+func (d *Decoder) readFrame() ([]byte, error) {
+	if err := d.fill(1); err != nil {
+		return nil, err
+	}
+
+	var size uint64
+	var read int
+	for {
+		size, read = binary.Uvarint(d.buf[d.off:])
+		if read > 0 {
+			break
+		}
+
+		available := len(d.buf) - d.off
+		if read < 0 || available >= maxVarIntLen {
+			return nil, ErrInvalidFrameSize
+		}
+
+		if err := d.fill(available + 1); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+	}
+
+	if size == 0 {
+		return nil, ErrInvalidFrameSize
+	}
+
+	if size > uint64(d.limits.MaxBlockSize) {
+		return nil, &LimitError{Limit: "MaxBlockSize", Value: int64(size), Max: d.limits.MaxBlockSize}
+	}
+
+	total := read + int(size)
+	if err := d.fill(total); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+
+	frame := d.buf[d.off : d.off+total : d.off+total]
+	d.off += total
+	return frame, nil
+}
+
+// fill ensures that at least n unread bytes are buffered, reading from the
+// underline reader as needed. Decoded values may reference the buffer, so
+// consumed bytes are never overwritten, instead a new buffer is allocated
+// when the current one has no room left. New buffers double the unread
+// bytes rather than growing to n at once, hence the memory held for a frame
+// is bounded by the bytes of it which have arrived, not by it's declared
+// length.
+func (d *Decoder) fill(n int) error {
+	var empty int
+	for len(d.buf)-d.off < n {
+		if d.err != nil {
+			return d.err
+		}
+
+		if cap(d.buf)-len(d.buf) < minReadSize {
+			unread := len(d.buf) - d.off
+
+			size := 2 * unread
+			if size > n {
+				size = n
+			}
+
+			buf := make([]byte, unread, size+minReadSize)
+			copy(buf, d.buf[d.off:])
+			d.buf = buf
+			d.off = 0
+		}
+
+		read, err := d.r.Read(d.buf[len(d.buf):cap(d.buf)])
+		d.buf = d.buf[:len(d.buf)+read]
+		if err != nil {
+			d.err = err
+		}
+
+		// readers returning no bytes and no error must not stall the
+		// Decoder forever, as with a bufio.Reader.
+		if read == 0 && err == nil {
+			empty++
+			if empty >= maxEmptyReads {
+				d.err = io.ErrNoProgress
+			}
+		} else {
+			empty = 0
+		}
+	}
+	return nil
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
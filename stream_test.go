@@ -0,0 +1,157 @@
+package voxa_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"reflect"
+	"runtime"
+	"testing"
+
+	"github.com/influx6/faux/tests"
+	"github.com/wirekit/voxa"
+	"github.com/wirekit/voxa/codecs"
+)
+
+// trickleReader returns at most one byte per read to force the Decoder
+// to refill it's buffer incrementally.
+type trickleReader struct {
+	r io.Reader
+}
+
+func (t trickleReader) Read(b []byte) (int, error) {
+	if len(b) > 1 {
+		b = b[:1]
+	}
+	return t.r.Read(b)
+}
+
+func TestEncoderDecoder(t *testing.T) {
+	records := []user{
+		{Name: "bob", Age: 20, Interests: []string{"daydreaming", "hacking"}},
+		{Name: "alice", Age: 32, Interests: []string{"running"}},
+		{Name: "rick", Age: 45, Interests: []string{"reading", "writing", "painting"}},
+	}
+
+	var buf bytes.Buffer
+	encoder := voxa.NewEncoder(&buf)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			tests.FailedWithError(err, "Should have successfully encoded record into stream")
+		}
+	}
+	tests.Passed("Should have successfully encoded records into stream")
+
+	var res []user
+	decoder := voxa.NewDecoder(trickleReader{r: &buf})
+	for decoder.More() {
+		var item user
+		if err := decoder.Decode(&item); err != nil {
+			tests.FailedWithError(err, "Should have successfully decoded record from stream")
+		}
+		res = append(res, item)
+	}
+	tests.Passed("Should have successfully decoded records from stream")
+
+	if !reflect.DeepEqual(res, records) {
+		tests.Info("Received: %#v", res)
+		tests.Info("Expected: %#v", records)
+		tests.Failed("Should have matching records between input and res")
+	}
+	tests.Passed("Should have matching records between input and res")
+
+	var item user
+	if err := decoder.Decode(&item); err != io.EOF {
+		tests.FailedWithError(err, "Should have received io.EOF at end of stream")
+	}
+	tests.Passed("Should have received io.EOF at end of stream")
+}
+
+func TestDecoder_DisallowUnknownFields(t *testing.T) {
+	var buf bytes.Buffer
+	if err := voxa.NewEncoder(&buf).Encode(user{Name: "bob", Age: 20}); err != nil {
+		tests.FailedWithError(err, "Should have successfully encoded record into stream")
+	}
+
+	decoder := voxa.NewDecoder(&buf)
+	decoder.DisallowUnknownFields()
+	decoder.SetLimits(voxa.Limits{MaxDepth: 32})
+
+	var named struct {
+		Name string `id:"1"`
+	}
+
+	var fieldErr *voxa.FieldError
+	if err := decoder.Decode(&named); !errors.As(err, &fieldErr) {
+		tests.FailedWithError(err, "Should have failed to decode unknown fields after limits were set")
+	}
+	tests.Passed("Should have failed to decode unknown fields after limits were set")
+}
+
+// stalledReader returns no bytes and no error on every read.
+type stalledReader struct{}
+
+func (stalledReader) Read(b []byte) (int, error) {
+	return 0, nil
+}
+
+func TestDecoder_NoProgress(t *testing.T) {
+	decoder := voxa.NewDecoder(stalledReader{})
+
+	var item user
+	if err := decoder.Decode(&item); err != io.ErrNoProgress {
+		tests.FailedWithError(err, "Should have received io.ErrNoProgress for reader returning no bytes")
+	}
+	tests.Passed("Should have received io.ErrNoProgress for reader returning no bytes")
+}
+
+func TestDecoder_TruncatedFrame(t *testing.T) {
+	encoded, err := voxa.Marshal(user{Name: "bob", Age: 20, Interests: []string{"hacking"}})
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully marshalled record")
+	}
+
+	decoder := voxa.NewDecoder(bytes.NewReader(encoded[:len(encoded)-3]))
+
+	var item user
+	if err := decoder.Decode(&item); err != io.ErrUnexpectedEOF {
+		tests.FailedWithError(err, "Should have received io.ErrUnexpectedEOF for truncated frame")
+	}
+	tests.Passed("Should have received io.ErrUnexpectedEOF for truncated frame")
+}
+
+func TestDecoder_DeclaredFrameSize(t *testing.T) {
+	// a frame declaring a length of 1GB which ends after a few bytes.
+	declared := append(codecs.EncodeVarInt64(1<<30), byte(voxa.Bytes), 0, 1, 2, 3)
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+
+	decoder := voxa.NewDecoder(bytes.NewReader(declared))
+
+	var item []byte
+	if err := decoder.Decode(&item); err != io.ErrUnexpectedEOF {
+		tests.FailedWithError(err, "Should have received io.ErrUnexpectedEOF for truncated frame")
+	}
+	tests.Passed("Should have received io.ErrUnexpectedEOF for truncated frame")
+
+	runtime.ReadMemStats(&after)
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 1<<20 {
+		tests.Info("Allocated: %d bytes", allocated)
+		tests.Failed("Should have allocated buffers for the received bytes rather than the declared length")
+	}
+	tests.Passed("Should have allocated buffers for the received bytes rather than the declared length")
+
+	large := bytes.Repeat([]byte("voxa"), 1<<16)
+	encoded, err := voxa.Marshal(large)
+	if err != nil {
+		tests.FailedWithError(err, "Should have successfully marshalled bytes")
+	}
+
+	decoder = voxa.NewDecoder(bytes.NewReader(encoded))
+	if err := decoder.Decode(&item); err != nil || !bytes.Equal(item, large) {
+		tests.Info("Received: %d bytes and %+q", len(item), err)
+		tests.Failed("Should have decoded frame larger than the initial buffer")
+	}
+	tests.Passed("Should have decoded frame larger than the initial buffer")
+}
@@ -0,0 +1,65 @@
+package voxa
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// TypeID identifies a type registered with RegisterType. It is encoded as a
+// varint before the values of that type held by an interface.
+type TypeID uint32
+
+var (
+	typesMu   sync.RWMutex
+	typesByID = map[TypeID]reflect.Type{}
+	idsByType = map[reflect.Type]TypeID{}
+)
+
+// RegisterType registers the type of provided value with provided TypeID,
+// such as `voxa.RegisterType(1, Circle{})`. Values of a registered type held
+// by an interface, such as an interface-typed field or the elements of a
+// []interface{}, are encoded as a Union frame marked with their TypeID, hence
+// they decode back into their type, including into fields of a custom
+// interface type.
+//
+// It panics if the TypeID or the type is already registered with another
+// type or TypeID. It is meant to be called during package initialization.
+func RegisterType(id TypeID, v interface{}) {
+	if v == nil {
+		panic("voxa: can not register type of nil value")
+	}
+
+	t := reflect.TypeOf(v)
+
+	typesMu.Lock()
+	defer typesMu.Unlock()
+
+	if registered, ok := typesByID[id]; ok && registered != t {
+		panic(fmt.Sprintf("voxa: type id %d is already registered for %q", id, registered))
+	}
+	if registered, ok := idsByType[t]; ok && registered != id {
+		panic(fmt.Sprintf("voxa: type %q is already registered with type id %d", t, registered))
+	}
+
+	typesByID[id] = t
+	idsByType[t] = id
+}
+
+// TypeByID returns the type registered with provided TypeID.
+func TypeByID(id TypeID) (reflect.Type, bool) {
+	typesMu.RLock()
+	defer typesMu.RUnlock()
+
+	t, ok := typesByID[id]
+	return t, ok
+}
+
+// TypeIDOf returns the TypeID provided type is registered with.
+func TypeIDOf(t reflect.Type) (TypeID, bool) {
+	typesMu.RLock()
+	defer typesMu.RUnlock()
+
+	id, ok := idsByType[t]
+	return id, ok
+}
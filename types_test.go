@@ -0,0 +1,51 @@
+package voxa_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/influx6/faux/tests"
+	"github.com/wirekit/voxa"
+)
+
+type circle struct {
+	Radius float64 `id:"1"`
+}
+
+type square struct {
+	Side float64 `id:"1"`
+}
+
+func expectPanic(fn func(), message string) {
+	defer func() {
+		if recover() == nil {
+			tests.Failed("%s", message)
+		}
+		tests.Passed("%s", message)
+	}()
+	fn()
+}
+
+func TestRegisterType(t *testing.T) {
+	voxa.RegisterType(1, circle{})
+	voxa.RegisterType(1, circle{})
+
+	if registered, ok := voxa.TypeByID(1); !ok || registered != reflect.TypeOf(circle{}) {
+		tests.Failed("Should have found type registered with type id")
+	}
+	tests.Passed("Should have found type registered with type id")
+
+	if id, ok := voxa.TypeIDOf(reflect.TypeOf(circle{})); !ok || id != 1 {
+		tests.Failed("Should have found type id of registered type")
+	}
+	tests.Passed("Should have found type id of registered type")
+
+	if _, ok := voxa.TypeIDOf(reflect.TypeOf(&circle{})); ok {
+		tests.Failed("Should not have found type id of pointer to registered type")
+	}
+	tests.Passed("Should not have found type id of pointer to registered type")
+
+	expectPanic(func() { voxa.RegisterType(1, square{}) }, "Should have panicked registering taken type id")
+	expectPanic(func() { voxa.RegisterType(2, circle{}) }, "Should have panicked registering type twice")
+	expectPanic(func() { voxa.RegisterType(3, nil) }, "Should have panicked registering nil value")
+}
@@ -9,6 +9,20 @@ const (
 	// with, to mark a field as matching a giving id from a struct to
 	// be converted or one to be deserialized with binary stream.
 	IDTagName = "id"
+
+	// DefaultTagName specifies the name of the tag holding the value a
+	// field is set to when it is missing from a decoded record.
+	DefaultTagName = "default"
+
+	// OmitEmptyOption is the option of the id tag which skips a field
+	// holding it's zero value when encoding, such as `id:"4,omitempty"`.
+	// It can not be used with a default, as the skipped zero value would
+	// decode into the default.
+	OmitEmptyOption = "omitempty"
+
+	// RequiredOption is the option of the id tag which fails decoding a
+	// record missing the field, such as `id:"4,required"`.
+	RequiredOption = "required"
 )
 
 var (
@@ -53,6 +67,11 @@ var (
 	// MaxICU128 is the maximum number of items a []complex128 can contain
 	// per block, with respect to the maximum allowed block size in MaxBlockSize.
 	MaxICU128Count = MaxBlockSize / 16
+
+	// MaxDepth is the maximum nesting depth of records, lists and maps that
+	// will be decoded from a binary stream, which stops deeply nested data
+	// from exhausting the stack.
+	MaxDepth = 10000
 )
 
 // constants of all Type types.
@@ -77,6 +96,40 @@ const (
 	List
 	Record
 	Time
+
+	// SInt, SInt32 and SInt64 are the zigzag encoded versions of the
+	// Int, Int32 and Int64 Atoms, which keep small negative numbers small.
+	// Int, Int32 and Int64 are only decoded to read older payloads.
+	SInt
+	SInt32
+	SInt64
+
+	// Map holds the entries of a map, each being a key frame followed by
+	// it's value frame.
+	Map
+
+	// Schema holds the HeaderCodec entries naming the fields of a record,
+	// followed by the record frame they describe.
+	Schema
+
+	// Null marks a nil pointer, interface, slice or map and holds no data.
+	Null
+
+	// Packed holds the elements of a slice or array of numbers or booleans
+	// as a single frame, declaring their Atom and count once, followed by
+	// their values without a frame each.
+	Packed
+
+	// Complex64 and Complex128 hold the real part of a complex number
+	// followed by it's imaginary part, as big endian floats of 4 and 8
+	// bytes.
+	Complex64
+	Complex128
+
+	// Union holds a value of a type registered with RegisterType which is
+	// held by an interface, as the varint TypeID of it's type followed by
+	// the frame of the value.
+	Union
 )
 
 // Atom is a int8 type declaration to represent different
@@ -125,14 +178,45 @@ func (a Atom) String() string {
 		return "record"
 	case Time:
 		return "time"
+	case SInt:
+		return "sint"
+	case SInt32:
+		return "sint32"
+	case SInt64:
+		return "sint64"
+	case Map:
+		return "map"
+	case Schema:
+		return "schema"
+	case Null:
+		return "null"
+	case Packed:
+		return "packed"
+	case Complex64:
+		return "complex64"
+	case Complex128:
+		return "complex128"
+	case Union:
+		return "union"
 	default:
 		return "invalid"
 	}
 }
 
-// FieldID sets a int8 type which is used to represent the
-// ID attached to a field name.
-type FieldID int8
+// FieldID sets a uint32 type which is used to represent the
+// ID attached to a field name. It is encoded as a varint, hence
+// ids below 128 take a single byte.
+type FieldID uint32
+
+// MaxFieldID is the largest FieldID.
+const MaxFieldID = FieldID(math.MaxUint32)
+
+// Unknown holds the frames of a record whose FieldID have no matching struct
+// field. A struct field of type Unknown tagged with `id:"-"` is filled with
+// them when the struct is decoded and they are appended verbatim to the
+// record when the struct is encoded, hence relays decoding records into an
+// older version of a struct do not lose the fields of newer versions.
+type Unknown []byte
 
 // HeaderCodec defines a interface which exposes two methods
 // to derive the representation of giving field name in a
@@ -184,3 +268,33 @@ type CodecTextual interface {
 	// and returning provided byte slice with new length.
 	NativeToTextual(interface{}, []byte) ([]byte, error)
 }
+
+// Marshaler is implemented by types which encode themselves into a voxa
+// frame. It is honoured by the codecs at every nesting level, taking
+// precedence over the encoding derived from the type's kind.
+type Marshaler interface {
+	// MarshalVoxa appends the complete frame of the value, that is
+	// `[Length VarInt][Atom][FieldID VarInt][Data...]`, marked with provided
+	// FieldID into provided byte slice, returning provided byte slice
+	// with new length.
+	MarshalVoxa(FieldID, []byte) ([]byte, error)
+}
+
+// Unmarshaler is implemented by types which decode themselves from a voxa
+// frame.
+type Unmarshaler interface {
+	// UnmarshalVoxa receives the complete frame written by MarshalVoxa,
+	// including it's length prefix. The byte slice must be copied if the
+	// value wishes to retain it.
+	UnmarshalVoxa([]byte) error
+}
+
+// LimitedUnmarshaler is implemented by Unmarshalers which enforce the Limits
+// of the decode they are part of, such as the types generated by voxagen.
+// The codecs call it in place of UnmarshalVoxa.
+type LimitedUnmarshaler interface {
+	// UnmarshalVoxaLimits receives the frame UnmarshalVoxa receives and the
+	// Limits of the decode, whose MaxDepth is the nesting depth left to the
+	// value rather than the depth of the whole payload.
+	UnmarshalVoxaLimits([]byte, Limits) error
+}